@@ -23,6 +23,7 @@ func init() {
 
 type docStorage struct {
 	response []json.RawMessage
+	delay    int
 }
 
 func (s *docStorage) GetType() string {
@@ -34,6 +35,11 @@ func (s *docStorage) LoadFixtures(location string, names []string) error {
 }
 
 func (s *docStorage) ExecuteQuery(query string) ([]json.RawMessage, error) {
+	if s.delay > 0 {
+		// emulate asynchronous write of the records
+		s.delay--
+		return []json.RawMessage{}, nil
+	}
 	if s.response == nil {
 		return nil, errors.New("fake error")
 	}
@@ -66,6 +72,12 @@ func (s *docStorage) setDBResponse(h endpoint.Helper) error {
 	return nil
 }
 
+// setDelayedDBResponse sets the response, which is returned by the third query.
+func (s *docStorage) setDelayedDBResponse(h endpoint.Helper) error {
+	s.delay = 2
+	return s.setDBResponse(h)
+}
+
 type errorChecker struct {
 	t         *testing.T
 	errorInfo string
@@ -128,7 +140,8 @@ func Test_Declarative(t *testing.T) {
 		DB:          storage,
 		TestHandler: checker.Handle,
 		HelperEndpoints: endpoint.EndpointMap{
-			"set_db_response": storage.setDBResponse,
+			"set_db_response":         storage.setDBResponse,
+			"set_delayed_db_response": storage.setDelayedDBResponse,
 		},
 	}
 
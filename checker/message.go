@@ -0,0 +1,24 @@
+package checker
+
+import (
+	"encoding/json"
+
+	"github.com/lansfy/gonkex/compare"
+	"github.com/lansfy/gonkex/models"
+)
+
+// CompareMessage compares message received by the test (e.g. WebSocket message or event of the stream)
+// with the expected one. Messages are compared as JSON values if both are valid JSON, otherwise as strings.
+func CompareMessage(expected, actual string, params models.ComparisonParams) []error {
+	var expectedValue, actualValue interface{}
+	if json.Unmarshal([]byte(expected), &expectedValue) != nil ||
+		json.Unmarshal([]byte(actual), &actualValue) != nil {
+		return compare.Compare(expected, actual, compare.Params{})
+	}
+
+	return compare.Compare(expectedValue, actualValue, compare.Params{
+		IgnoreValues:         params.IgnoreValuesChecking(),
+		IgnoreArraysOrdering: params.IgnoreArraysOrdering(),
+		DisallowExtraFields:  params.DisallowExtraFields(),
+	})
+}
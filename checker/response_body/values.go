@@ -10,6 +10,8 @@ import (
 	"github.com/lansfy/gonkex/colorize"
 	"github.com/lansfy/gonkex/models"
 	"github.com/lansfy/gonkex/types"
+
+	"github.com/tidwall/gjson"
 )
 
 func ExtractValues(varsToSet map[string]string, result *models.Result) (map[string]string, []error) {
@@ -78,8 +80,24 @@ func processPath(path string, result *models.Result) (string, error) {
 		}
 		return "", colorize.NewError("%s header does not include expected cookie %s",
 			colorize.Cyan("Set-Cookie"), colorize.Green(path))
+	case "mock":
+		// path consists of the name of the mock and the path in the array of its requests
+		name, requestsPath, _ := strings.Cut(path, ".")
+		requests, ok := result.MockRequests[name]
+		if !ok {
+			return "", colorize.NewEntityError("unknown mock name %s", name)
+		}
+		if requestsPath == "" {
+			return requests, nil
+		}
+		res := gjson.Get(requests, requestsPath)
+		if !res.Exists() {
+			return "", colorize.NewError("path %s does not exist in requests of mock %s",
+				colorize.Cyan("$."+requestsPath), colorize.Green(name))
+		}
+		return res.String(), nil
 	default:
-		return "", fmt.Errorf("unexpected path prefix '%s' (allowed only [body header cookie])", prefix)
+		return "", fmt.Errorf("unexpected path prefix '%s' (allowed only [body header cookie mock])", prefix)
 	}
 }
 
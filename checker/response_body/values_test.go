@@ -367,7 +367,7 @@ func Test_ExtractValuesFromHeaders(t *testing.T) {
 				"var1": "header:Test-Header-1",
 				"var3": "wrong-prefix:Test-Header-1",
 			},
-			wantErr: "variable 'var3': unexpected path prefix 'wrong-prefix' (allowed only [body header cookie])",
+			wantErr: "variable 'var3': unexpected path prefix 'wrong-prefix' (allowed only [body header cookie mock])",
 		},
 	}
 
@@ -457,3 +457,60 @@ func Test_ExtractValuesFromCookie(t *testing.T) {
 		})
 	}
 }
+
+func Test_ExtractValuesFromMockRequests(t *testing.T) {
+	result := &models.Result{
+		MockRequests: map[string]string{
+			"payments": `[{"method": "POST", "path": "/charges", "body": {"id": "ch_1", "amount": 10}}]`,
+		},
+	}
+
+	tests := []struct {
+		description string
+		varsToSet   map[string]string
+		want        map[string]string
+		wantErr     string
+	}{
+		{
+			description: "variables with valid paths",
+			varsToSet: map[string]string{
+				"var1": "mock:payments.0.body.id",
+				"var2": "mock: payments.#",
+				"var3": "mock:payments.0.body",
+			},
+			want: map[string]string{
+				"var1": "ch_1",
+				"var2": "1",
+				"var3": `{"id": "ch_1", "amount": 10}`,
+			},
+		},
+		{
+			description: "variables with unknown mock",
+			varsToSet: map[string]string{
+				"var1": "mock:orders.0.body",
+			},
+			wantErr: "variable 'var1': unknown mock name 'orders'",
+		},
+		{
+			description: "variables with wrong path",
+			varsToSet: map[string]string{
+				"var1": "mock:payments.1.body",
+			},
+			wantErr: "variable 'var1': path '$.1.body' does not exist in requests of mock payments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got, err := ExtractValues(tt.varsToSet, result)
+
+			if tt.wantErr != "" {
+				require.Equal(t, 1, len(err))
+				require.EqualError(t, err[0], tt.wantErr)
+			} else {
+				require.Equal(t, 0, len(err))
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package response_db
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/lansfy/gonkex/checker"
 	"github.com/lansfy/gonkex/colorize"
@@ -11,6 +12,8 @@ import (
 	"github.com/lansfy/gonkex/storage"
 )
 
+const defaultEventuallyInterval = 100 * time.Millisecond
+
 func NewChecker(db storage.StorageInterface) checker.CheckerInterface {
 	return &responseDbChecker{
 		db: db,
@@ -25,7 +28,7 @@ func (c *responseDbChecker) Check(t models.TestInterface, result *models.Result)
 	var errors []error
 	for idx, dbCheck := range t.GetDatabaseChecks() {
 		path := fmt.Sprintf("$.dbChecks[%d]", idx)
-		errs, err := c.check(path, dbCheck, result)
+		errs, err := c.checkEventually(path, dbCheck, result)
 		if err != nil {
 			return nil, err
 		}
@@ -35,32 +38,56 @@ func (c *responseDbChecker) Check(t models.TestInterface, result *models.Result)
 	return errors, nil
 }
 
-func (c *responseDbChecker) check(path string, t models.DatabaseCheck, result *models.Result) ([]error, error) {
+// checkEventually repeats the check until it passes or the timeout of 'eventually' section expires.
+// Only the result of the last attempt is reported.
+func (c *responseDbChecker) checkEventually(path string, t models.DatabaseCheck, result *models.Result) ([]error, error) {
+	timeout := t.GetEventually().Timeout()
+	interval := t.GetEventually().Interval()
+	if interval == 0 {
+		interval = defaultEventuallyInterval
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		dbResult, errs, err := c.check(path, t)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining := time.Until(deadline)
+		if len(errs) == 0 || remaining <= 0 {
+			result.DatabaseResult = append(result.DatabaseResult, dbResult)
+			return errs, nil
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (c *responseDbChecker) check(path string, t models.DatabaseCheck) (models.DatabaseResult, []error, error) {
+	dbResult := models.DatabaseResult{Query: t.DbQueryString(), Response: []string{}}
 	if t.DbQueryString() == "" {
-		return nil, createDefinitionError(path, colorize.NewEntityError("%s key required", "dbQuery"))
+		return dbResult, nil, createDefinitionError(path, colorize.NewEntityError("%s key required", "dbQuery"))
 	}
 
 	// parse expected DB response
 	expectedItems, err := unmarshalArray(path, t.DbResponseJson())
 	if err != nil {
-		return nil, err
+		return dbResult, nil, err
 	}
 
 	// get real DB response
 	actualItems, err := makeQuery(path, c.db, t.DbQueryString())
 	if err != nil {
-		result.DatabaseResult = append(result.DatabaseResult,
-			models.DatabaseResult{Query: t.DbQueryString(), Response: []string{}},
-		)
-		return []error{err}, nil
+		return dbResult, []error{err}, nil
 	}
 
-	result.DatabaseResult = append(result.DatabaseResult,
-		models.DatabaseResult{Query: t.DbQueryString(), Response: toStringArray(actualItems)},
-	)
+	dbResult.Response = toStringArray(actualItems)
 
 	if len(expectedItems) != len(actualItems) {
-		return []error{createDifferentLengthError(path, expectedItems, actualItems)}, nil
+		return dbResult, []error{createDifferentLengthError(path, expectedItems, actualItems)}, nil
 	}
 
 	cmpOptions := t.GetComparisonParams()
@@ -75,7 +102,7 @@ func (c *responseDbChecker) check(path string, t models.DatabaseCheck, result *m
 		errs[idx] = colorize.NewEntityError("database check for %s", path+".dbResponse").WithSubError(errs[idx])
 	}
 
-	return errs, nil
+	return dbResult, errs, nil
 }
 
 func toStringArray(src []interface{}) []string {
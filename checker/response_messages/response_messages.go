@@ -0,0 +1,191 @@
+package response_messages
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lansfy/gonkex/checker"
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/compare"
+	"github.com/lansfy/gonkex/messagebus"
+	"github.com/lansfy/gonkex/models"
+)
+
+const defaultTimeout = 5 * time.Second
+
+func NewChecker(bus messagebus.MessageBusInterface) checker.CheckerInterface {
+	return &responseMessagesChecker{
+		bus: bus,
+	}
+}
+
+type responseMessagesChecker struct {
+	bus messagebus.MessageBusInterface
+}
+
+func (c *responseMessagesChecker) Check(t models.TestInterface, result *models.Result) ([]error, error) {
+	var errors []error
+	for idx, check := range t.GetMessageChecks() {
+		path := fmt.Sprintf("$.messageChecks[%d]", idx)
+		errors = append(errors, c.check(path, check, result)...)
+	}
+	return errors, nil
+}
+
+func (c *responseMessagesChecker) check(path string, check models.MessageCheck, result *models.Result) []error {
+	timeout := check.Timeout()
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	expected := check.Messages()
+	received, err := c.bus.Captured(check.Topic(), len(expected), timeout)
+	if err != nil {
+		return []error{colorize.NewEntityError("failed %s", "message check").WithSubError(
+			colorize.NewPathError(path, fmt.Errorf("read messages of topic '%s': %w", check.Topic(), err)))}
+	}
+
+	// key and headers are compared only for expected messages, which specify them
+	expectedItems := make([]interface{}, len(expected))
+	for i, msg := range expected {
+		expectedItems[i] = toExpectedItem(msg)
+	}
+
+	// received messages are shown with key and headers if any expected message specifies them
+	withKey, withHeaders := false, false
+	for _, msg := range expected {
+		withKey = withKey || msg.Key() != ""
+		withHeaders = withHeaders || len(msg.Headers()) != 0
+	}
+	receivedItems := make([]interface{}, len(received))
+	for i, msg := range received {
+		receivedItems[i] = toItem(msg.Key, msg.Value, msg.Headers, withKey, withHeaders)
+	}
+
+	result.Messages = append(result.Messages, models.MessagesResult{
+		Topic:    check.Topic(),
+		Messages: toStringArray(receivedItems),
+	})
+
+	if len(expectedItems) != len(receivedItems) {
+		return []error{createDifferentLengthError(path, check.Topic(), expectedItems, receivedItems)}
+	}
+
+	cmpOptions := check.GetComparisonParams()
+	params := compare.Params{
+		IgnoreValues:         cmpOptions.IgnoreValuesChecking(),
+		IgnoreArraysOrdering: cmpOptions.IgnoreArraysOrdering(),
+		DisallowExtraFields:  cmpOptions.DisallowExtraFields(),
+	}
+	errs := compare.Compare(expectedItems, pairItems(expected, expectedItems, received, params), params)
+	for idx := range errs {
+		errs[idx] = colorize.NewEntityError("message check for %s", path+".messages").WithSubError(errs[idx])
+	}
+	return errs
+}
+
+// pairItems converts received messages to the objects, which are compared with expected items.
+// Every received message includes key and headers only if the expected message,
+// which it's compared with, specifies them. If the ordering of messages is ignored,
+// every expected message is paired with the first unpaired received message, which matches it.
+func pairItems(expected []models.BusMessage, expectedItems []interface{},
+	received []messagebus.Message, params compare.Params) []interface{} {
+	order := make([]int, len(expected))
+	for i := range order {
+		order[i] = i
+	}
+
+	if params.IgnoreArraysOrdering {
+		paired := make([]bool, len(received))
+		var unmatched []int
+		for i, msg := range expected {
+			order[i] = -1
+			for j := range received {
+				if !paired[j] && len(compare.Compare(expectedItems[i], toActualItem(received[j], msg), params)) == 0 {
+					order[i] = j
+					paired[j] = true
+					break
+				}
+			}
+			if order[i] == -1 {
+				unmatched = append(unmatched, i)
+			}
+		}
+		// the rest of received messages are compared with unmatched expected ones
+		for j := range received {
+			if !paired[j] {
+				order[unmatched[0]] = j
+				unmatched = unmatched[1:]
+			}
+		}
+	}
+
+	items := make([]interface{}, len(expected))
+	for i, msg := range expected {
+		items[i] = toActualItem(received[order[i]], msg)
+	}
+	return items
+}
+
+func toExpectedItem(msg models.BusMessage) interface{} {
+	return toItem(msg.Key(), msg.Value(), msg.Headers(), msg.Key() != "", len(msg.Headers()) != 0)
+}
+
+func toActualItem(msg messagebus.Message, expected models.BusMessage) interface{} {
+	return toItem(msg.Key, msg.Value, msg.Headers, expected.Key() != "", len(expected.Headers()) != 0)
+}
+
+// toItem converts the message to the object, which is used for comparison.
+// The value, which is valid JSON, is stored as JSON, otherwise it's stored as a string.
+func toItem(key, value string, headers map[string]string, withKey, withHeaders bool) interface{} {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		parsed = value
+	}
+	item := map[string]interface{}{"value": parsed}
+	if withKey {
+		item["key"] = key
+	}
+	if withHeaders {
+		values := map[string]interface{}{}
+		for name, value := range headers {
+			values[name] = value
+		}
+		item["headers"] = values
+	}
+	return item
+}
+
+func toStringArray(src []interface{}) []string {
+	result := make([]string, len(src))
+	for idx := range src {
+		data, _ := json.Marshal(src[idx])
+		result[idx] = string(data)
+	}
+	return result
+}
+
+func sprintWithSingleQuotes(items []interface{}) []string {
+	result := []string{"["}
+	for _, item := range toStringArray(items) {
+		result = append(result, " '"+item+"',")
+	}
+	result = append(result, "]")
+	return result
+}
+
+func createDifferentLengthError(path, topic string, expected, actual []interface{}) error {
+	tail := colorize.MakeColorDiff(
+		"\n\n   diff (--- expected vs +++ actual):\n",
+		sprintWithSingleQuotes(expected),
+		sprintWithSingleQuotes(actual),
+	)
+
+	return colorize.NewPathError(path, colorize.NewEntityNotEqualError(
+		"quantity of %s does not match:",
+		fmt.Sprintf("messages in topic %s", topic),
+		len(expected),
+		len(actual),
+	).WithPostfix(tail))
+}
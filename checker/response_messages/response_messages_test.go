@@ -0,0 +1,135 @@
+package response_messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/messagebus"
+	"github.com/lansfy/gonkex/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMessage struct {
+	key     string
+	value   string
+	headers map[string]string
+}
+
+func (m *fakeMessage) Key() string {
+	return m.key
+}
+
+func (m *fakeMessage) Value() string {
+	return m.value
+}
+
+func (m *fakeMessage) Headers() map[string]string {
+	return m.headers
+}
+
+type fakeCheck struct {
+	messages []models.BusMessage
+	params   fakeParams
+}
+
+func (c *fakeCheck) Topic() string {
+	return "events"
+}
+
+func (c *fakeCheck) Messages() []models.BusMessage {
+	return c.messages
+}
+
+func (c *fakeCheck) Timeout() time.Duration {
+	return time.Millisecond
+}
+
+func (c *fakeCheck) GetComparisonParams() models.ComparisonParams {
+	return c.params
+}
+
+type fakeParams struct {
+	ignoreArraysOrdering bool
+}
+
+func (fakeParams) IgnoreValuesChecking() bool   { return false }
+func (p fakeParams) IgnoreArraysOrdering() bool { return p.ignoreArraysOrdering }
+func (fakeParams) DisallowExtraFields() bool    { return true }
+
+type fakeTest struct {
+	models.TestInterface
+	checks []models.MessageCheck
+}
+
+func (t *fakeTest) GetMessageChecks() []models.MessageCheck {
+	return t.checks
+}
+
+func TestChecker_DisallowExtraFields(t *testing.T) {
+	received := []messagebus.Message{
+		{Key: "1", Value: `{"id": 1}`, Headers: map[string]string{"type": "created"}},
+		{Key: "2", Value: `{"id": 2}`, Headers: map[string]string{"type": "updated"}},
+	}
+	expected := []models.BusMessage{
+		&fakeMessage{key: "1", value: `{"id": 1}`},
+		&fakeMessage{value: `{"id": 2}`, headers: map[string]string{"type": "updated"}},
+	}
+	reversed := []models.BusMessage{expected[1], expected[0]}
+
+	tests := []struct {
+		description string
+		messages    []models.BusMessage
+		params      fakeParams
+		wantErr     string
+	}{
+		{
+			description: "key and headers are compared only for messages which specify them",
+			messages:    expected,
+		},
+		{
+			description: "ordering is ignored",
+			messages:    reversed,
+			params:      fakeParams{ignoreArraysOrdering: true},
+		},
+		{
+			description: "ordering is checked",
+			messages:    reversed,
+			wantErr:     "path '$[0].value.id': values do not match",
+		},
+		{
+			description: "different key",
+			messages: []models.BusMessage{
+				&fakeMessage{key: "2", value: `{"id": 1}`},
+				expected[1],
+			},
+			params:  fakeParams{ignoreArraysOrdering: true},
+			wantErr: "path '$[0].key': values do not match",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			bus := messagebus.NewMemoryBus()
+			require.NoError(t, bus.StartCapture([]string{"events"}))
+			require.NoError(t, bus.Publish("events", received))
+
+			test := &fakeTest{checks: []models.MessageCheck{&fakeCheck{messages: tt.messages, params: tt.params}}}
+			result := &models.Result{}
+			errs, err := NewChecker(bus).Check(test, result)
+			require.NoError(t, err)
+			if tt.wantErr == "" {
+				require.Empty(t, errs)
+			} else {
+				require.NotEmpty(t, errs)
+				require.Contains(t, errs[0].Error(), tt.wantErr)
+			}
+			require.Equal(t, []models.MessagesResult{{
+				Topic: "events",
+				Messages: []string{
+					`{"headers":{"type":"created"},"key":"1","value":{"id":1}}`,
+					`{"headers":{"type":"updated"},"key":"2","value":{"id":2}}`,
+				},
+			}}, result.Messages)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package response_mock_requests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lansfy/gonkex/checker"
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/compare"
+	"github.com/lansfy/gonkex/models"
+)
+
+func NewChecker() checker.CheckerInterface {
+	return &responseMockRequestsChecker{}
+}
+
+type responseMockRequestsChecker struct{}
+
+func (c *responseMockRequestsChecker) Check(t models.TestInterface, result *models.Result) ([]error, error) {
+	var errors []error
+	for _, check := range t.GetMockRequests() {
+		path := fmt.Sprintf("$.mockRequests.%s", check.ServiceName())
+		errs, err := c.check(path, check, result)
+		if err != nil {
+			return nil, err
+		}
+		errors = append(errors, errs...)
+	}
+	return errors, nil
+}
+
+func (c *responseMockRequestsChecker) check(path string, check models.MockRequestsCheck,
+	result *models.Result) ([]error, error) {
+	data, ok := result.MockRequests[check.ServiceName()]
+	if !ok {
+		return nil, colorize.NewPathError(path, fmt.Errorf("unknown mock name '%s'", check.ServiceName()))
+	}
+
+	var actual []interface{}
+	if err := json.Unmarshal([]byte(data), &actual); err != nil {
+		return nil, fmt.Errorf("gonkex internal error: decode requests of mock '%s': %w", check.ServiceName(), err)
+	}
+
+	var errs []error
+	if count, ok := check.Count(); ok && count != len(actual) {
+		errs = append(errs, colorize.NewPathError(path+".count", colorize.NewEntityNotEqualError(
+			"quantity of %s does not match:",
+			fmt.Sprintf("requests to mock %s", check.ServiceName()),
+			count,
+			len(actual),
+		)))
+	}
+
+	expectedRequests := check.Requests()
+	if expectedRequests == nil {
+		return errs, nil
+	}
+
+	expected := make([]interface{}, len(expectedRequests))
+	for i, request := range expectedRequests {
+		expected[i] = toItem(request)
+	}
+
+	cmpOptions := check.GetComparisonParams()
+	cmpErrs := compare.Compare(expected, actual, compare.Params{
+		IgnoreValues:         cmpOptions.IgnoreValuesChecking(),
+		IgnoreArraysOrdering: cmpOptions.IgnoreArraysOrdering(),
+		DisallowExtraFields:  cmpOptions.DisallowExtraFields(),
+	})
+	for idx := range cmpErrs {
+		cmpErrs[idx] = colorize.NewEntityError("mock requests check for %s", path+".requests").WithSubError(cmpErrs[idx])
+	}
+	return append(errs, cmpErrs...), nil
+}
+
+// toItem converts the expected request to the object, which is compared with the request received by mock
+// (see mocks.RequestRecord.ToMap). Only the fields specified in the expected request are compared.
+func toItem(request models.MockRequest) interface{} {
+	item := map[string]interface{}{}
+	if request.Method() != "" {
+		item["method"] = request.Method()
+	}
+	if request.Path() != "" {
+		item["path"] = request.Path()
+	}
+	if request.Query() != "" {
+		item["query"] = request.Query()
+	}
+	if len(request.Headers()) != 0 {
+		headers := map[string]interface{}{}
+		for name, value := range request.Headers() {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+		item["headers"] = headers
+	}
+	if request.Body() != "" {
+		var body interface{}
+		if err := json.Unmarshal([]byte(request.Body()), &body); err != nil {
+			body = request.Body()
+		}
+		item["body"] = body
+	}
+	return item
+}
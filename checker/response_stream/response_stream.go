@@ -0,0 +1,138 @@
+package response_stream
+
+import (
+	"fmt"
+
+	"github.com/lansfy/gonkex/checker"
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/models"
+)
+
+func NewChecker() checker.CheckerInterface {
+	return &responseStreamChecker{}
+}
+
+type responseStreamChecker struct{}
+
+func (c *responseStreamChecker) Check(t models.TestInterface, result *models.Result) ([]error, error) {
+	stream := t.GetStream()
+	if stream == nil {
+		return nil, nil
+	}
+
+	var errs []error
+	if stream.IgnoreOrdering() {
+		errs = checkUnordered(stream.Events(), result.Stream)
+	} else {
+		errs = checkOrdered(stream.Events(), result.Stream)
+	}
+	if result.StreamError != nil {
+		errs = append(errs, colorize.NewPathError("$.stream", result.StreamError))
+	}
+	return errs, nil
+}
+
+func checkOrdered(expected []models.ExpectedEvent, received []models.StreamEvent) []error {
+	var errs []error
+	for idx := range received {
+		if idx >= len(expected) {
+			break
+		}
+		path := fmt.Sprintf("$.stream.events[%d]", idx)
+		for _, err := range compareEvent(expected[idx], &received[idx]) {
+			errs = append(errs, colorize.NewEntityError("received event for %s", path).WithSubError(err))
+		}
+	}
+	return errs
+}
+
+// checkUnordered finds matching received event for every expected event.
+func checkUnordered(expected []models.ExpectedEvent, received []models.StreamEvent) []error {
+	if len(received) < len(expected) {
+		// not enough events are received, it's reported as the stream error
+		return nil
+	}
+	matcher := NewUnorderedMatcher(expected)
+	for i := range received {
+		matcher.Add(received[i])
+	}
+	var errs []error
+	for _, idx := range matcher.Missing() {
+		errs = append(errs, colorize.NewEntityError("matching event for %s was not received",
+			fmt.Sprintf("$.stream.events[%d]", idx)))
+	}
+	return errs
+}
+
+// UnorderedMatcher assigns distinct received event to every expected event, if the order of events is ignored.
+// It finds maximum matching of the bipartite graph, so a loose expectation (e.g. with $matchRegexp)
+// doesn't take the only event, which a stricter expectation can match. Received events, which don't
+// match any expected event (e.g. heartbeats), are ignored.
+type UnorderedMatcher struct {
+	expected []models.ExpectedEvent
+	matches  [][]int // indexes of expected events matched by every received event
+	assigned []int   // index of received event assigned to every expected event (-1 if none)
+	count    int
+}
+
+// NewUnorderedMatcher creates matcher without received events.
+func NewUnorderedMatcher(expected []models.ExpectedEvent) *UnorderedMatcher {
+	assigned := make([]int, len(expected))
+	for i := range assigned {
+		assigned[i] = -1
+	}
+	return &UnorderedMatcher{expected: expected, assigned: assigned}
+}
+
+// Add adds the received event and updates the matching. Only the new event is compared
+// with expected events: the matching can grow only by augmenting path, which starts at the new event.
+func (m *UnorderedMatcher) Add(event models.StreamEvent) {
+	var matches []int
+	for idx, expected := range m.expected {
+		if len(compareEvent(expected, &event)) == 0 {
+			matches = append(matches, idx)
+		}
+	}
+	m.matches = append(m.matches, matches)
+	if m.augment(len(m.matches)-1, make([]bool, len(m.expected))) {
+		m.count++
+	}
+}
+
+// augment looks for augmenting path from the received event and applies it.
+func (m *UnorderedMatcher) augment(received int, visited []bool) bool {
+	for _, idx := range m.matches[received] {
+		if visited[idx] {
+			continue
+		}
+		visited[idx] = true
+		if m.assigned[idx] == -1 || m.augment(m.assigned[idx], visited) {
+			m.assigned[idx] = received
+			return true
+		}
+	}
+	return false
+}
+
+// Matched returns the number of expected events, which have assigned received event.
+func (m *UnorderedMatcher) Matched() int {
+	return m.count
+}
+
+// Missing returns indexes of expected events without assigned received event.
+func (m *UnorderedMatcher) Missing() []int {
+	var missing []int
+	for idx, received := range m.assigned {
+		if received == -1 {
+			missing = append(missing, idx)
+		}
+	}
+	return missing
+}
+
+func compareEvent(expected models.ExpectedEvent, actual *models.StreamEvent) []error {
+	if expected.Event() != "" && expected.Event() != actual.Event {
+		return []error{colorize.NewEntityNotEqualError("%s does not match:", "event type", expected.Event(), actual.Event)}
+	}
+	return checker.CompareMessage(expected.Data(), actual.Data, expected.GetComparisonParams())
+}
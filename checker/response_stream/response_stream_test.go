@@ -0,0 +1,70 @@
+package response_stream
+
+import (
+	"testing"
+
+	"github.com/lansfy/gonkex/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEvent struct {
+	event string
+	data  string
+}
+
+func (e *fakeEvent) Event() string {
+	return e.event
+}
+
+func (e *fakeEvent) Data() string {
+	return e.data
+}
+
+func (e *fakeEvent) GetComparisonParams() models.ComparisonParams {
+	return fakeParams{}
+}
+
+type fakeParams struct{}
+
+func (fakeParams) IgnoreValuesChecking() bool { return false }
+func (fakeParams) IgnoreArraysOrdering() bool { return false }
+func (fakeParams) DisallowExtraFields() bool  { return false }
+
+func Test_UnorderedMatcher(t *testing.T) {
+	expected := []models.ExpectedEvent{
+		&fakeEvent{data: `{"id": "$matchRegexp(^[0-9]+$)"}`},
+		&fakeEvent{data: `{"id": 1}`},
+		&fakeEvent{event: "done", data: `{}`},
+	}
+	m := NewUnorderedMatcher(expected)
+	require.Equal(t, []int{0, 1, 2}, m.Missing())
+
+	// the loose expectation takes the first event
+	m.Add(models.StreamEvent{Data: `{"id": 1}`})
+	require.Equal(t, 1, m.Matched())
+
+	// unrelated event is ignored
+	m.Add(models.StreamEvent{Data: `{"heartbeat": true}`})
+	require.Equal(t, 1, m.Matched())
+
+	// the first event is reassigned to the strict expectation
+	m.Add(models.StreamEvent{Data: `{"id": 2}`})
+	require.Equal(t, 2, m.Matched())
+	require.Equal(t, []int{2}, m.Missing())
+
+	m.Add(models.StreamEvent{Event: "done", Data: `{}`})
+	require.Equal(t, 3, m.Matched())
+	require.Empty(t, m.Missing())
+}
+
+func Test_UnorderedMatcher_Missing(t *testing.T) {
+	m := NewUnorderedMatcher([]models.ExpectedEvent{
+		&fakeEvent{data: `{"id": 1}`},
+		&fakeEvent{data: `{"id": 1}`},
+	})
+	m.Add(models.StreamEvent{Data: `{"id": 1}`})
+	m.Add(models.StreamEvent{Data: `{"id": 2}`})
+	require.Equal(t, 1, m.Matched())
+	require.Equal(t, []int{1}, m.Missing())
+}
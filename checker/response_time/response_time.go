@@ -0,0 +1,29 @@
+package response_time
+
+import (
+	"github.com/lansfy/gonkex/checker"
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/models"
+)
+
+func NewChecker() checker.CheckerInterface {
+	return &responseTimeChecker{}
+}
+
+type responseTimeChecker struct{}
+
+func (c *responseTimeChecker) Check(t models.TestInterface, result *models.Result) ([]error, error) {
+	maxDuration := t.GetResponseTime().MaxDuration()
+	if maxDuration <= 0 || result.Timings.Total <= maxDuration {
+		return nil, nil
+	}
+
+	return []error{
+		colorize.NewError(
+			"request took %s, but %s is %s",
+			colorize.Red(result.Timings.Total.String()),
+			colorize.Cyan("responseTime.maxDuration"),
+			colorize.Green(maxDuration.String()),
+		),
+	}, nil
+}
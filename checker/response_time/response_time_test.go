@@ -0,0 +1,60 @@
+package response_time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResponseTime time.Duration
+
+func (d fakeResponseTime) MaxDuration() time.Duration {
+	return time.Duration(d)
+}
+
+type fakeTest struct {
+	models.TestInterface
+	maxDuration time.Duration
+}
+
+func (t *fakeTest) GetResponseTime() models.ResponseTime {
+	return fakeResponseTime(t.maxDuration)
+}
+
+func Test_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxDuration time.Duration
+		total       time.Duration
+		wantErr     string
+	}{
+		{"no limit", 0, time.Second, ""},
+		{"fast request", 200 * time.Millisecond, 150 * time.Millisecond, ""},
+		{"exact limit", 200 * time.Millisecond, 200 * time.Millisecond, ""},
+		{
+			"slow request",
+			200 * time.Millisecond,
+			253 * time.Millisecond,
+			"request took 253ms, but 'responseTime.maxDuration' is 200ms",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := NewChecker().Check(
+				&fakeTest{maxDuration: tt.maxDuration},
+				&models.Result{Timings: models.Timings{Total: tt.total}},
+			)
+			require.NoError(t, err)
+			if tt.wantErr == "" {
+				require.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			require.Equal(t, tt.wantErr, colorize.ProcessWithTemplate(errs[0], colorize.NoColorMap))
+		})
+	}
+}
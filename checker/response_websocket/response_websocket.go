@@ -0,0 +1,50 @@
+package response_websocket
+
+import (
+	"fmt"
+
+	"github.com/lansfy/gonkex/checker"
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/models"
+)
+
+func NewChecker() checker.CheckerInterface {
+	return &responseWebSocketChecker{}
+}
+
+type responseWebSocketChecker struct{}
+
+func (c *responseWebSocketChecker) Check(t models.TestInterface, result *models.Result) ([]error, error) {
+	ws := t.GetWebSocket()
+	if ws == nil {
+		return nil, nil
+	}
+
+	received := map[int]*models.WebSocketMessage{}
+	for idx := range result.WebSocket {
+		received[result.WebSocket[idx].Step] = &result.WebSocket[idx]
+	}
+
+	var errs []error
+	for idx, step := range ws.Steps() {
+		path := fmt.Sprintf("$.websocket.steps[%d]", idx)
+		msg, ok := received[idx]
+		if ok && msg.Error != nil {
+			// sending or receiving failed, the rest of steps wasn't performed
+			errs = append(errs, colorize.NewPathError(path, msg.Error))
+			break
+		}
+		if step.Receive() == "" {
+			// successful send step has no entry
+			continue
+		}
+		if !ok {
+			// conversation was interrupted by the previous error
+			break
+		}
+		for _, err := range checker.CompareMessage(step.Receive(), msg.Message, step.GetComparisonParams()) {
+			errs = append(errs, colorize.NewEntityError("received message for %s", path).WithSubError(err))
+		}
+	}
+	return errs, nil
+}
@@ -0,0 +1,106 @@
+package response_websocket
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStep struct {
+	send    string
+	receive string
+}
+
+func (s *fakeStep) Send() string {
+	return s.send
+}
+
+func (s *fakeStep) Receive() string {
+	return s.receive
+}
+
+func (s *fakeStep) Timeout() time.Duration {
+	return 0
+}
+
+func (s *fakeStep) GetComparisonParams() models.ComparisonParams {
+	return nil
+}
+
+type fakeWebSocket []models.WebSocketStep
+
+func (w fakeWebSocket) Steps() []models.WebSocketStep {
+	return w
+}
+
+type fakeTest struct {
+	models.TestInterface
+	steps fakeWebSocket
+}
+
+func (t *fakeTest) GetWebSocket() models.WebSocketParams {
+	return t.steps
+}
+
+func Test_Check(t *testing.T) {
+	steps := fakeWebSocket{
+		&fakeStep{send: "ping"},
+		&fakeStep{receive: "pong"},
+		&fakeStep{send: "bye"},
+	}
+	tests := []struct {
+		name     string
+		steps    fakeWebSocket
+		messages []models.WebSocketMessage
+		wantErrs []string
+	}{
+		{
+			name:     "all steps passed",
+			steps:    steps,
+			messages: []models.WebSocketMessage{{Step: 1, Message: "pong"}},
+		},
+		{
+			name:     "send failed",
+			steps:    steps,
+			messages: []models.WebSocketMessage{{Step: 0, Error: errors.New("send message: broken pipe")}},
+			wantErrs: []string{"path '$.websocket.steps[0]': send message: broken pipe"},
+		},
+		{
+			name:  "last send failed",
+			steps: steps,
+			messages: []models.WebSocketMessage{
+				{Step: 1, Message: "pong"},
+				{Step: 2, Error: errors.New("send message: broken pipe")},
+			},
+			wantErrs: []string{"path '$.websocket.steps[2]': send message: broken pipe"},
+		},
+		{
+			name:     "only send steps",
+			steps:    fakeWebSocket{&fakeStep{send: "first"}, &fakeStep{send: "second"}},
+			messages: []models.WebSocketMessage{{Step: 1, Error: errors.New("send message: connection reset")}},
+			wantErrs: []string{"path '$.websocket.steps[1]': send message: connection reset"},
+		},
+		{
+			name:     "receive failed",
+			steps:    steps,
+			messages: []models.WebSocketMessage{{Step: 1, Error: errors.New("message was not received within 1s")}},
+			wantErrs: []string{"path '$.websocket.steps[1]': message was not received within 1s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := NewChecker().Check(&fakeTest{steps: tt.steps}, &models.Result{WebSocket: tt.messages})
+			require.NoError(t, err)
+			var actual []string
+			for _, e := range errs {
+				actual = append(actual, colorize.ProcessWithTemplate(e, colorize.NoColorMap))
+			}
+			require.Equal(t, tt.wantErrs, actual)
+		})
+	}
+}
@@ -0,0 +1,409 @@
+// Package cli implements the command line interface of the standalone gonkex tool.
+// It allows to run Gonkex tests against services written in any language without writing Go code.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lansfy/gonkex/mocks"
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/output"
+	"github.com/lansfy/gonkex/output/allure"
+	"github.com/lansfy/gonkex/output/junit"
+	"github.com/lansfy/gonkex/output/terminal"
+	"github.com/lansfy/gonkex/runner"
+	"github.com/lansfy/gonkex/storage"
+	"github.com/lansfy/gonkex/testloader"
+	"github.com/lansfy/gonkex/testloader/yaml_file"
+)
+
+// ErrTestsFailed is returned by Run when some tests failed.
+var ErrTestsFailed = errors.New("some tests failed")
+
+// StorageFactory creates storage for the provided connection string (DSN).
+// If the storage implements io.Closer, it's closed when the run ends.
+type StorageFactory func(dsn string) (storage.StorageInterface, error)
+
+// Opts holds configuration of the command line interface.
+type Opts struct {
+	// Storages maps the value of "db-type" flag to the function which creates corresponding storage.
+	Storages map[string]StorageFactory
+	// DefaultDBType is used as the value of "db-type" flag, if only "db_dsn" flag is set.
+	DefaultDBType string
+	// Output is the destination for test results and usage messages (os.Stdout by default).
+	Output io.Writer
+	// Context stops the watch mode when canceled (the watch mode is also stopped by interrupt signal).
+	Context context.Context
+}
+
+type config struct {
+	host        string
+	testsDir    string
+	fixturesDir string
+	envFile     string
+	dbDSN       string
+	dbType      string
+	mocks       string
+	mocksAdmin  string
+	adminRemote bool
+	filter      string
+	tags        string
+	allureDir   string
+	junitFile   string
+	parallel    int
+	onFail      string
+	debug       bool
+	client      runner.ClientOpts
+	load        runner.LoadOpts
+	watch       bool
+	interval    time.Duration
+	record      bool
+	update      bool
+	patterns    bool
+}
+
+var onFailPolicies = map[string]runner.OnFailPolicy{
+	"skip-file": runner.PolicySkipFile,
+	"stop":      runner.PolicyStop,
+	"continue":  runner.PolicyContinue,
+}
+
+func parseArgs(args []string, opts *Opts) (*config, error) {
+	cfg := &config{}
+	fs := flag.NewFlagSet("gonkex", flag.ContinueOnError)
+	fs.SetOutput(opts.Output)
+	fs.StringVar(&cfg.host, "host", "", "base URL of the tested service, e.g. http://localhost:8080 (required)")
+	fs.StringVar(&cfg.testsDir, "tests", "", "path to the file or directory with tests (required)")
+	fs.StringVar(&cfg.fixturesDir, "fixtures", "", "path to the directory with fixtures")
+	fs.StringVar(&cfg.envFile, "env-file", "", "path to the env-file with variables")
+	fs.StringVar(&cfg.dbDSN, "db_dsn", "", "connection string for the database")
+	dbTypeUsage := "type of the database, allowed values: " + strings.Join(storageNames(opts), ", ")
+	if opts.DefaultDBType != "" {
+		dbTypeUsage += " (" + opts.DefaultDBType + " if only -db_dsn is set)"
+	}
+	fs.StringVar(&cfg.dbType, "db-type", "", dbTypeUsage)
+	fs.StringVar(&cfg.mocks, "mocks", "", "comma-separated list of mock services (name or name:port), "+
+		"address of every mock is exported as "+runner.MockEnvironmentPrefix+"<NAME> environment variable")
+	fs.StringVar(&cfg.mocksAdmin, "mocks-admin", "", "if non-empty, serve admin API of mocks on specified address, e.g. localhost:8090 "+
+		"(API is available under "+mocks.AdminPrefix+" path)")
+	fs.BoolVar(&cfg.adminRemote, "mocks-admin-remote", false, "allow admin API of mocks on non-loopback address "+
+		"(API has no authentication, don't expose it to untrusted networks)")
+	fs.StringVar(&cfg.filter, "filter", "", "run only test files which have this string in path")
+	fs.StringVar(&cfg.tags, "tags", "", "run only tests which match tags expression, e.g. \"smoke && !slow\"")
+	fs.StringVar(&cfg.allureDir, "allure-dir", "", "if non-empty, create allure report in specified folder")
+	fs.StringVar(&cfg.junitFile, "junit-file", "", "if non-empty, create JUnit XML report in specified file")
+	fs.StringVar(&cfg.client.CAFile, "ca-file", "", "PEM file with CA certificates, enables verification of the server certificate")
+	fs.StringVar(&cfg.client.CertFile, "cert-file", "", "PEM file with client certificate")
+	fs.StringVar(&cfg.client.KeyFile, "key-file", "", "PEM file with private key of client certificate")
+	fs.StringVar(&cfg.client.ServerName, "server-name", "", "name used to verify the server certificate")
+	fs.StringVar(&cfg.client.Protocol, "protocol", runner.ProtocolHTTP1, "HTTP protocol: http1, http2 or h2c")
+	fs.IntVar(&cfg.parallel, "parallel", 0, "number of test files executed concurrently")
+	fs.StringVar(&cfg.onFail, "on-fail", "skip-file", "behavior on test failure: skip-file, stop or continue")
+	fs.BoolVar(&cfg.debug, "debug", false, "show results of successful tests too")
+	fs.IntVar(&cfg.load.Concurrency, "load-concurrency", 1, "number of virtual users in load mode")
+	fs.Float64Var(&cfg.load.Rate, "load-rate", 0, "maximum number of requests per second in load mode (0 means no limit)")
+	fs.DurationVar(&cfg.load.Duration, "load-duration", 0, "duration of the load run, enables load mode")
+	fs.IntVar(&cfg.load.Iterations, "load-iterations", 0, "number of iterations made by every virtual user, enables load mode")
+	fs.BoolVar(&cfg.watch, "watch", false, "rerun affected tests every time test files, fixtures or mock files change")
+	fs.BoolVar(&cfg.record, "record", false, "write responses of tests without expected response to test files")
+	fs.BoolVar(&cfg.update, "update", false, "rewrite files from responseFile section with actual responses")
+	fs.BoolVar(&cfg.patterns, "record-patterns", false, "replace UUIDs and timestamps in recorded responses with patterns")
+	fs.DurationVar(&cfg.interval, "watch-interval", 500*time.Millisecond, "interval between checks of files in watch mode")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if cfg.host == "" {
+		return nil, errors.New("flag -host is required")
+	}
+	if cfg.testsDir == "" {
+		return nil, errors.New("flag -tests is required")
+	}
+	if _, ok := onFailPolicies[cfg.onFail]; !ok {
+		return nil, fmt.Errorf("unknown -on-fail value %q", cfg.onFail)
+	}
+	if cfg.dbDSN != "" && cfg.dbType == "" {
+		cfg.dbType = opts.DefaultDBType
+	}
+	if (cfg.dbDSN == "") != (cfg.dbType == "") {
+		return nil, errors.New("flags -db_dsn and -db-type must be used together")
+	}
+	if cfg.watch && (cfg.load.Duration > 0 || cfg.load.Iterations > 0) {
+		return nil, errors.New("watch mode can't be used with load mode")
+	}
+	if cfg.watch && (cfg.allureDir != "" || cfg.junitFile != "") {
+		return nil, errors.New("reports can't be created in watch mode")
+	}
+	return cfg, nil
+}
+
+func storageNames(opts *Opts) []string {
+	names := []string{}
+	for name := range opts.Storages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func createStorage(cfg *config, opts *Opts) (storage.StorageInterface, error) {
+	if cfg.dbType == "" {
+		return nil, nil
+	}
+	factory, ok := opts.Storages[cfg.dbType]
+	if !ok {
+		return nil, fmt.Errorf("unknown db type %q (allowed only %v)", cfg.dbType, storageNames(opts))
+	}
+	db, err := factory(cfg.dbDSN)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s database: %w", cfg.dbType, err)
+	}
+	return db, nil
+}
+
+func startMocks(cfg *config) (*mocks.Mocks, error) {
+	var names []string
+	for _, name := range strings.Split(cfg.mocks, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	m := mocks.NewNop(names...)
+	if err := m.Start(); err != nil {
+		return nil, fmt.Errorf("start mocks: %w", err)
+	}
+	if err := m.RegisterEnvironmentVariables(runner.MockEnvironmentPrefix); err != nil {
+		m.Shutdown()
+		return nil, err
+	}
+	if cfg.mocksAdmin != "" {
+		if err := m.StartAdminServer(cfg.mocksAdmin, mocks.NewYamlLoader(nil),
+			&mocks.AdminServerOpts{AllowRemote: cfg.adminRemote}); err != nil {
+			m.Shutdown()
+			return nil, fmt.Errorf("start admin server of mocks: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// Run parses command line arguments, starts mocks, executes tests and reports results.
+// It returns ErrTestsFailed if some tests failed and flag.ErrHelp if help was requested.
+func Run(args []string, opts *Opts) error {
+	o := Opts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Output == nil {
+		o.Output = os.Stdout
+	}
+
+	cfg, err := parseArgs(args, &o)
+	if err != nil {
+		return err
+	}
+
+	m, err := startMocks(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Shutdown()
+
+	if cfg.envFile != "" {
+		if err := runner.RegisterEnvironmentVariables(cfg.envFile, false); err != nil {
+			return err
+		}
+	}
+
+	db, err := createStorage(cfg, &o)
+	if err != nil {
+		return err
+	}
+	if closer, ok := db.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	loader := yaml_file.NewLoader(cfg.testsDir)
+	if cfg.filter != "" {
+		loader.SetFilter(func(fileName string) bool {
+			return strings.Contains(fileName, cfg.filter)
+		})
+	}
+
+	var recordMode yaml_file.RecordMode
+	if cfg.record {
+		recordMode |= yaml_file.RecordMissing
+	}
+	if cfg.update {
+		recordMode |= yaml_file.RecordFiles
+	}
+
+	var recorder testloader.RecorderInterface
+	if recordMode != 0 {
+		recorder = yaml_file.NewRecorder(&yaml_file.RecorderOpts{Mode: recordMode, Patterns: cfg.patterns})
+	}
+
+	r := runner.New(loader, &runner.RunnerOpts{
+		Host:         cfg.host,
+		FixturesDir:  cfg.fixturesDir,
+		DB:           db,
+		Mocks:        m,
+		MocksLoader:  mocks.NewYamlLoader(nil),
+		OnFailPolicy: onFailPolicies[cfg.onFail],
+		Parallel:     cfg.parallel,
+		Tags:         cfg.tags,
+		Recorder:     recorder,
+		Client:       cfg.client,
+	})
+
+	if cfg.load.Duration > 0 || cfg.load.Iterations > 0 {
+		return runLoad(r, cfg, &o)
+	}
+
+	summary := &summaryOutput{}
+	r.AddOutput(terminal.NewOutput(&terminal.OutputOpts{
+		ShowSuccess: cfg.debug,
+		Writer:      o.Output,
+	}), summary)
+
+	if cfg.watch {
+		return runWatch(r, cfg, &o, summary)
+	}
+
+	finalize, err := addReports(r, cfg)
+	if err != nil {
+		return err
+	}
+
+	err = r.Run()
+	if finalizeErr := finalize(); finalizeErr != nil && err == nil {
+		err = finalizeErr
+	}
+
+	_, _ = fmt.Fprintln(o.Output, summary.String())
+	if err != nil {
+		if summary.failed != 0 {
+			return ErrTestsFailed
+		}
+		return err
+	}
+	return nil
+}
+
+func runLoad(r *runner.Runner, cfg *config, opts *Opts) error {
+	report, err := r.RunLoad(&cfg.load)
+	if err != nil {
+		return err
+	}
+	if err := report.Print(opts.Output); err != nil {
+		return err
+	}
+	if report.Total.Errors != 0 {
+		return ErrTestsFailed
+	}
+	return nil
+}
+
+func runWatch(r *runner.Runner, cfg *config, opts *Opts, summary *summaryOutput) error {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	paths := []string{cfg.testsDir}
+	if cfg.fixturesDir != "" {
+		paths = append(paths, cfg.fixturesDir)
+	}
+
+	return r.Watch(ctx, &runner.WatchOpts{
+		Paths:    paths,
+		Interval: cfg.interval,
+		OnRun: func(changed []string, err error) {
+			if len(changed) != 0 {
+				_, _ = fmt.Fprintf(opts.Output, "Changed files: %s\n", strings.Join(changed, ", "))
+			}
+			if err != nil && summary.failed == 0 {
+				_, _ = fmt.Fprintln(opts.Output, err.Error())
+			}
+			_, _ = fmt.Fprintln(opts.Output, summary.String())
+			_, _ = fmt.Fprintln(opts.Output, "Watching for changes...")
+			*summary = summaryOutput{}
+		},
+	})
+}
+
+func addReports(r *runner.Runner, cfg *config) (func() error, error) {
+	var finalizers []func() error
+	finalize := func() error {
+		var errs []string
+		for _, f := range finalizers {
+			if err := f(); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) != 0 {
+			return fmt.Errorf("write report: %s", strings.Join(errs, "; "))
+		}
+		return nil
+	}
+
+	if cfg.allureDir != "" {
+		o, err := allure.NewOutput("Gonkex", cfg.allureDir)
+		if err != nil {
+			return nil, err
+		}
+		r.AddOutput(o)
+		finalizers = append(finalizers, o.Finalize)
+	}
+
+	if cfg.junitFile != "" {
+		o, err := junit.NewOutput("Gonkex", cfg.junitFile)
+		if err != nil {
+			return nil, err
+		}
+		r.AddOutput(o)
+		finalizers = append(finalizers, o.Finalize)
+	}
+	return finalize, nil
+}
+
+var _ output.ExtendedOutputInterface = (*summaryOutput)(nil)
+
+// summaryOutput counts results of executed tests.
+type summaryOutput struct {
+	passed  int
+	failed  int
+	skipped int
+}
+
+func (s *summaryOutput) BeforeTest(t models.TestInterface) error {
+	if t.GetStatus() == models.StatusSkipped || t.GetStatus() == models.StatusBroken {
+		s.skipped++
+	}
+	return nil
+}
+
+func (s *summaryOutput) Process(_ models.TestInterface, result *models.Result) error {
+	if result.Passed() {
+		s.passed++
+	} else {
+		s.failed++
+	}
+	return nil
+}
+
+func (s *summaryOutput) String() string {
+	return fmt.Sprintf("passed: %d, failed: %d, skipped: %d", s.passed, s.failed, s.skipped)
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/lansfy/gonkex/storage"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStorage struct {
+	dsn    string
+	loaded []string
+	closed bool
+}
+
+func (s *fakeStorage) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *fakeStorage) GetType() string {
+	return "fake"
+}
+
+func (s *fakeStorage) LoadFixtures(_ string, names []string) error {
+	s.loaded = append(s.loaded, names...)
+	return nil
+}
+
+func (s *fakeStorage) ExecuteQuery(_ string) ([]json.RawMessage, error) {
+	return nil, nil
+}
+
+func testServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status": "ok"}`))
+	}))
+}
+
+func Test_Run(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	db := &fakeStorage{}
+	opts := &Opts{
+		Storages: map[string]StorageFactory{
+			"fake": func(dsn string) (storage.StorageInterface, error) {
+				db.dsn = dsn
+				return db, nil
+			},
+		},
+		Output: &bytes.Buffer{},
+	}
+
+	err := Run([]string{
+		"-host", srv.URL,
+		"-tests", "testdata/passed.yaml",
+		"-db-type", "fake",
+		"-db_dsn", "fake://localhost",
+		"-mocks", "backend",
+	}, opts)
+	require.NoError(t, err)
+	require.Equal(t, "fake://localhost", db.dsn)
+	require.Equal(t, []string{"fixture1"}, db.loaded)
+	require.True(t, db.closed)
+	require.Contains(t, opts.Output.(*bytes.Buffer).String(), "passed: 1, failed: 0, skipped: 0")
+	require.NotEmpty(t, os.Getenv("GONKEX_MOCK_BACKEND"))
+}
+
+func Test_Run_DefaultDBType(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	db := &fakeStorage{}
+	err := Run([]string{
+		"-host", srv.URL,
+		"-tests", "testdata/passed.yaml",
+		"-db_dsn", "fake://localhost",
+	}, &Opts{
+		Storages: map[string]StorageFactory{
+			"fake": func(dsn string) (storage.StorageInterface, error) {
+				db.dsn = dsn
+				return db, nil
+			},
+		},
+		DefaultDBType: "fake",
+		Output:        &bytes.Buffer{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "fake://localhost", db.dsn)
+	require.Equal(t, []string{"fixture1"}, db.loaded)
+}
+
+func Test_Run_Failed(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	err := Run([]string{
+		"-host", srv.URL,
+		"-tests", "testdata",
+		"-filter", "failed",
+		"-on-fail", "continue",
+	}, &Opts{Output: out})
+	require.ErrorIs(t, err, ErrTestsFailed)
+	require.Contains(t, out.String(), "passed: 0, failed: 1, skipped: 0")
+}
+
+func Test_Run_Errors(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{
+			args:    []string{"-tests", "testdata"},
+			wantErr: "flag -host is required",
+		},
+		{
+			args:    []string{"-host", "http://localhost"},
+			wantErr: "flag -tests is required",
+		},
+		{
+			args:    []string{"-host", "http://localhost", "-tests", "testdata", "-on-fail", "wrong"},
+			wantErr: `unknown -on-fail value "wrong"`,
+		},
+		{
+			args:    []string{"-host", "http://localhost", "-tests", "testdata", "-db-type", "fake"},
+			wantErr: "flags -db_dsn and -db-type must be used together",
+		},
+		{
+			args:    []string{"-host", "http://localhost", "-tests", "testdata", "-db_dsn", "dsn"},
+			wantErr: "flags -db_dsn and -db-type must be used together",
+		},
+		{
+			args:    []string{"-host", "http://localhost", "-tests", "testdata", "-db-type", "unknown", "-db_dsn", "dsn"},
+			wantErr: `unknown db type "unknown" (allowed only [fake])`,
+		},
+		{
+			args:    []string{"-host", "http://localhost", "-tests", "testdata", "-watch", "-load-iterations", "1"},
+			wantErr: "watch mode can't be used with load mode",
+		},
+		{
+			args:    []string{"-host", "http://localhost", "-tests", "testdata", "-watch", "-junit-file", "report.xml"},
+			wantErr: "reports can't be created in watch mode",
+		},
+		{
+			args:    []string{"-host", "http://localhost", "-tests", "testdata", "-mocks-admin", "localhost"},
+			wantErr: "start admin server of mocks: listen tcp: address localhost: missing port in address",
+		},
+		{
+			args:    []string{"-host", "http://localhost", "-tests", "testdata", "-mocks-admin", ":0"},
+			wantErr: "start admin server of mocks: address ':0' is not loopback, admin API can be exposed to other hosts only explicitly",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			err := Run(tt.args, &Opts{
+				Storages: map[string]StorageFactory{
+					"fake": func(string) (storage.StorageInterface, error) {
+						return nil, errors.New("unexpected call")
+					},
+				},
+				Output: &bytes.Buffer{},
+			})
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_Run_Help(t *testing.T) {
+	err := Run([]string{"-h"}, &Opts{Output: &bytes.Buffer{}})
+	require.ErrorIs(t, err, flag.ErrHelp)
+}
+
+func Test_Run_Load(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	err := Run([]string{
+		"-host", srv.URL,
+		"-tests", "testdata/passed.yaml",
+		"-load-concurrency", "2",
+		"-load-iterations", "3",
+	}, &Opts{Output: out})
+	require.EqualError(t, err, "no tests for load mode: all tests were filtered out or use fixtures, database checks or mocks")
+
+	err = Run([]string{
+		"-host", srv.URL,
+		"-tests", "testdata/failed.yaml",
+		"-load-concurrency", "2",
+		"-load-iterations", "3",
+	}, &Opts{Output: out})
+	require.ErrorIs(t, err, ErrTestsFailed)
+	require.Contains(t, out.String(), "Load results: 6 requests in")
+}
+
+func Test_Run_Watch(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	// watch mode exits after the first run, because context is already canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out := &bytes.Buffer{}
+	err := Run([]string{
+		"-host", srv.URL,
+		"-tests", "testdata/failed.yaml",
+		"-watch",
+	}, &Opts{Output: out, Context: ctx})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "passed: 0, failed: 1, skipped: 0\nWatching for changes...")
+}
@@ -0,0 +1,112 @@
+// Command gonkex runs Gonkex tests against a service written in any language.
+//
+// Usage:
+//
+//	gonkex -host http://localhost:8080 -tests ./tests [-fixtures ./fixtures] [-db_dsn ... [-db-type mysql]]
+//
+// Run "gonkex -h" to see all supported flags.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lansfy/gonkex/cli"
+	"github.com/lansfy/gonkex/storage"
+	mongostorage "github.com/lansfy/gonkex/storage/addons/mongo"
+	redisstorage "github.com/lansfy/gonkex/storage/addons/redis"
+	"github.com/lansfy/gonkex/storage/addons/sqldb"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pingTimeout limits the check of the database connection at startup.
+const pingTimeout = 10 * time.Second
+
+// closingStorage closes the client of the storage when the run ends.
+type closingStorage struct {
+	storage.StorageInterface
+	close func() error
+}
+
+func (s *closingStorage) Close() error {
+	return s.close()
+}
+
+func sqlStorage(dbType sqldb.SQLType, driverName string) cli.StorageFactory {
+	return func(dsn string) (storage.StorageInterface, error) {
+		db, err := sql.Open(driverName, dsn)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Ping(); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		return &closingStorage{sqldb.NewStorage(dbType, db, nil), db.Close}, nil
+	}
+}
+
+func mongoStorage(dsn string) (storage.StorageInterface, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(dsn))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+	closeClient := func() error {
+		return client.Disconnect(context.Background())
+	}
+	return &closingStorage{mongostorage.NewStorage(client, nil), closeClient}, nil
+}
+
+func redisStorage(dsn string) (storage.StorageInterface, error) {
+	opts, err := redis.ParseURL(dsn)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return &closingStorage{redisstorage.NewStorage(client, nil), client.Close}, nil
+}
+
+func main() {
+	err := cli.Run(os.Args[1:], &cli.Opts{
+		Storages: map[string]cli.StorageFactory{
+			string(sqldb.PostgreSQL):  sqlStorage(sqldb.PostgreSQL, "postgres"),
+			string(sqldb.TimescaleDB): sqlStorage(sqldb.TimescaleDB, "postgres"),
+			string(sqldb.MySQL):       sqlStorage(sqldb.MySQL, "mysql"),
+			string(sqldb.MariaDB):     sqlStorage(sqldb.MariaDB, "mysql"),
+			"mongo":                   mongoStorage,
+			"redis":                   redisStorage,
+		},
+		DefaultDBType: string(sqldb.PostgreSQL),
+	})
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,19 @@
+module github.com/lansfy/gonkex/messagebus/addons/kafka
+
+go 1.23
+
+require (
+	github.com/lansfy/gonkex v0.6.5
+	github.com/segmentio/kafka-go v0.4.51
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/lansfy/gonkex => ../../..
@@ -0,0 +1,232 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lansfy/gonkex/messagebus"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MessageBus is the message bus for Kafka cluster. Messages are published by the writer
+// and captured by readers of all partitions of the captured topics.
+type MessageBus struct {
+	brokers   []string
+	dialer    *kafka.Dialer
+	writer    *kafka.Writer
+	newReader func(topic string, partition int) partitionReader
+
+	mutex   sync.Mutex
+	capture *messagebus.Capture
+	cancel  context.CancelFunc
+	readers sync.WaitGroup
+}
+
+// MessageBusOpts contains optional parameters of the message bus.
+type MessageBusOpts struct {
+	// Dialer is used for connections to brokers (kafka.DefaultDialer if not set).
+	Dialer *kafka.Dialer
+}
+
+// NewMessageBus creates message bus for Kafka cluster with the specified brokers.
+// Close must be called after all tests are executed.
+func NewMessageBus(brokers []string, opts *MessageBusOpts) *MessageBus {
+	b := &MessageBus{
+		brokers: brokers,
+		dialer:  kafka.DefaultDialer,
+	}
+	if opts != nil && opts.Dialer != nil {
+		b.dialer = opts.Dialer
+	}
+	b.newReader = b.newPartitionReader
+
+	b.writer = &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Balancer: &kafka.Hash{},
+		Transport: &kafka.Transport{
+			Dial: b.dialer.DialFunc,
+			TLS:  b.dialer.TLS,
+			SASL: b.dialer.SASLMechanism,
+		},
+		RequiredAcks: kafka.RequireAll,
+	}
+	return b
+}
+
+// GetType returns "kafka".
+func (b *MessageBus) GetType() string {
+	return "kafka"
+}
+
+// Publish writes messages to the topic and waits for acknowledgement from all replicas.
+func (b *MessageBus) Publish(topic string, messages []messagebus.Message) error {
+	items := make([]kafka.Message, len(messages))
+	for i, msg := range messages {
+		items[i] = kafka.Message{
+			Topic: topic,
+			Value: []byte(msg.Value),
+		}
+		if msg.Key != "" {
+			items[i].Key = []byte(msg.Key)
+		}
+		for name, value := range msg.Headers {
+			items[i].Headers = append(items[i].Headers, kafka.Header{Key: name, Value: []byte(value)})
+		}
+	}
+	return b.writer.WriteMessages(context.Background(), items...)
+}
+
+// StartCapture remembers the last offsets of all partitions of the topics and starts reading
+// of the messages written after them.
+func (b *MessageBus) StartCapture(topics []string) error {
+	b.stopCapture()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	capture := messagebus.NewCapture(topics)
+	for _, topic := range topics {
+		offsets, err := b.lastOffsets(ctx, topic)
+		if err != nil {
+			cancel()
+			b.readers.Wait()
+			return err
+		}
+		for partition, offset := range offsets {
+			b.readers.Add(1)
+			go b.readPartition(ctx, capture, topic, partition, offset)
+		}
+	}
+
+	b.mutex.Lock()
+	b.capture = capture
+	b.cancel = cancel
+	b.mutex.Unlock()
+	return nil
+}
+
+// Captured waits for messages of the topic, see messagebus.Capture.Wait.
+func (b *MessageBus) Captured(topic string, count int, timeout time.Duration) ([]messagebus.Message, error) {
+	b.mutex.Lock()
+	capture := b.capture
+	b.mutex.Unlock()
+
+	if capture == nil {
+		return nil, errors.New("capture of messages is not started")
+	}
+	return capture.Wait(topic, count, timeout)
+}
+
+// Close stops capturing of messages and closes connections to brokers.
+func (b *MessageBus) Close() error {
+	b.stopCapture()
+	return b.writer.Close()
+}
+
+func (b *MessageBus) stopCapture() {
+	b.mutex.Lock()
+	cancel := b.cancel
+	b.capture = nil
+	b.cancel = nil
+	b.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		b.readers.Wait()
+	}
+}
+
+// lastOffsets returns offsets of the next messages for every partition of the topic.
+func (b *MessageBus) lastOffsets(ctx context.Context, topic string) (map[int]int64, error) {
+	wrap := func(err error) error {
+		return fmt.Errorf("read offsets of topic '%s': %w", topic, err)
+	}
+
+	if len(b.brokers) == 0 {
+		return nil, wrap(errors.New("list of brokers is empty"))
+	}
+	conn, err := b.dialer.DialContext(ctx, "tcp", b.brokers[0])
+	if err != nil {
+		return nil, wrap(err)
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(topic)
+	if err != nil {
+		return nil, wrap(err)
+	}
+
+	offsets := map[int]int64{}
+	for _, p := range partitions {
+		leader, err := b.dialer.DialLeader(ctx, "tcp", b.brokers[0], topic, p.ID)
+		if err != nil {
+			return nil, wrap(err)
+		}
+		offset, err := leader.ReadLastOffset()
+		_ = leader.Close()
+		if err != nil {
+			return nil, wrap(err)
+		}
+		offsets[p.ID] = offset
+	}
+	return offsets, nil
+}
+
+// partitionReader reads messages of one partition, it's implemented by kafka.Reader.
+type partitionReader interface {
+	SetOffset(offset int64) error
+	ReadMessage(ctx context.Context) (kafka.Message, error)
+	Close() error
+}
+
+func (b *MessageBus) newPartitionReader(topic string, partition int) partitionReader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   b.brokers,
+		Dialer:    b.dialer,
+		Topic:     topic,
+		Partition: partition,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+		MaxWait:   100 * time.Millisecond,
+	})
+}
+
+// readPartition adds messages of the partition to the capture until the context is canceled.
+// If reading fails, the error is stored in the capture, so waiters of the topic don't wait for the timeout.
+func (b *MessageBus) readPartition(ctx context.Context, capture *messagebus.Capture,
+	topic string, partition int, offset int64) {
+	defer b.readers.Done()
+
+	wrap := func(err error) error {
+		return fmt.Errorf("read partition %d of topic '%s': %w", partition, topic, err)
+	}
+
+	reader := b.newReader(topic, partition)
+	defer reader.Close()
+
+	if err := reader.SetOffset(offset); err != nil {
+		capture.Fail(topic, wrap(err))
+		return
+	}
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			// context is canceled by stopCapture, other errors aren't retried by the reader
+			if ctx.Err() == nil {
+				capture.Fail(topic, wrap(err))
+			}
+			return
+		}
+		headers := map[string]string{}
+		for _, h := range msg.Headers {
+			headers[h.Key] = string(h.Value)
+		}
+		capture.Add(topic, messagebus.Message{
+			Key:     string(msg.Key),
+			Value:   string(msg.Value),
+			Headers: headers,
+		})
+	}
+}
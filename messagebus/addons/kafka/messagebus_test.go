@@ -0,0 +1,145 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/messagebus"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeReader struct {
+	setOffsetErr error
+	messages     []kafka.Message
+	readErr      error
+	offset       int64
+}
+
+func (r *fakeReader) SetOffset(offset int64) error {
+	r.offset = offset
+	return r.setOffsetErr
+}
+
+func (r *fakeReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.messages) != 0 {
+		msg := r.messages[0]
+		r.messages = r.messages[1:]
+		return msg, nil
+	}
+	if r.readErr != nil {
+		return kafka.Message{}, r.readErr
+	}
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) Close() error {
+	return nil
+}
+
+func startRead(b *MessageBus, reader *fakeReader) (*messagebus.Capture, context.CancelFunc) {
+	b.newReader = func(string, int) partitionReader {
+		return reader
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	capture := messagebus.NewCapture([]string{"events"})
+	b.readers.Add(1)
+	go b.readPartition(ctx, capture, "events", 2, 10)
+	return capture, cancel
+}
+
+func TestMessageBus_ReadPartition(t *testing.T) {
+	b := NewMessageBus([]string{"localhost:9092"}, nil)
+	defer b.Close()
+
+	reader := &fakeReader{
+		messages: []kafka.Message{
+			{Key: []byte("1"), Value: []byte("first"), Headers: []kafka.Header{{Key: "type", Value: []byte("created")}}},
+			{Value: []byte("second")},
+		},
+	}
+	capture, cancel := startRead(b, reader)
+
+	messages, err := capture.Wait("events", 2, 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []messagebus.Message{
+		{Key: "1", Value: "first", Headers: map[string]string{"type": "created"}},
+		{Value: "second", Headers: map[string]string{}},
+	}, messages)
+
+	// cancellation of the capture isn't an error
+	cancel()
+	b.readers.Wait()
+	require.Equal(t, int64(10), reader.offset)
+	messages, err = capture.Wait("events", 3, time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+}
+
+func TestMessageBus_ReadPartitionErrors(t *testing.T) {
+	tests := []struct {
+		description string
+		reader      *fakeReader
+		wantErr     string
+		wantCount   int
+	}{
+		{
+			description: "set offset failed",
+			reader:      &fakeReader{setOffsetErr: errors.New("offset out of range")},
+			wantErr:     "read partition 2 of topic 'events': offset out of range",
+		},
+		{
+			description: "read failed",
+			reader: &fakeReader{
+				messages: []kafka.Message{{Value: []byte("first")}},
+				readErr:  errors.New("connection reset"),
+			},
+			wantErr:   "read partition 2 of topic 'events': connection reset",
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			b := NewMessageBus([]string{"localhost:9092"}, nil)
+			defer b.Close()
+
+			capture, cancel := startRead(b, tt.reader)
+			defer cancel()
+
+			start := time.Now()
+			messages, err := capture.Wait("events", 2, 5*time.Second)
+			require.EqualError(t, err, tt.wantErr)
+			require.Len(t, messages, tt.wantCount)
+			require.Less(t, time.Since(start), time.Second)
+		})
+	}
+}
+
+func TestMessageBus_Errors(t *testing.T) {
+	b := NewMessageBus(nil, nil)
+	defer b.Close()
+
+	require.Equal(t, "kafka", b.GetType())
+
+	_, err := b.Captured("events", 1, time.Millisecond)
+	require.EqualError(t, err, "capture of messages is not started")
+
+	err = b.StartCapture([]string{"events"})
+	require.EqualError(t, err, "read offsets of topic 'events': list of brokers is empty")
+}
+
+func TestNewMessageBus_Dialer(t *testing.T) {
+	dialer := &kafka.Dialer{ClientID: "gonkex", Timeout: time.Second}
+	b := NewMessageBus([]string{"localhost:9092"}, &MessageBusOpts{Dialer: dialer})
+	defer b.Close()
+
+	require.Same(t, dialer, b.dialer)
+	require.Equal(t, kafka.TCP("localhost:9092"), b.writer.Addr)
+
+	b = NewMessageBus([]string{"localhost:9092"}, nil)
+	require.Same(t, kafka.DefaultDialer, b.dialer)
+}
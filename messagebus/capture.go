@@ -0,0 +1,92 @@
+package messagebus
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Capture collects messages of the captured topics and allows to wait for them.
+// It can be used by implementations of MessageBusInterface.
+type Capture struct {
+	mutex    sync.Mutex
+	messages map[string][]Message
+	errs     map[string]error
+	changed  chan struct{}
+}
+
+// NewCapture creates capture of the specified topics.
+func NewCapture(topics []string) *Capture {
+	c := &Capture{
+		messages: map[string][]Message{},
+		errs:     map[string]error{},
+		changed:  make(chan struct{}),
+	}
+	for _, topic := range topics {
+		c.messages[topic] = []Message{}
+	}
+	return c
+}
+
+// Add stores the message, if the topic is captured.
+func (c *Capture) Add(topic string, msg Message) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.messages[topic]; !ok {
+		return
+	}
+	c.messages[topic] = append(c.messages[topic], msg)
+	c.notify()
+}
+
+// Fail stores the error of reading of the topic, e.g. when connection to the broker is lost.
+// Waiters of the topic, which don't have enough messages, get the error instead of waiting for the timeout.
+// Only the first error of the topic is kept.
+func (c *Capture) Fail(topic string, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.messages[topic]; !ok || c.errs[topic] != nil {
+		return
+	}
+	c.errs[topic] = err
+	c.notify()
+}
+
+// notify wakes up all waiters, must be called under lock.
+func (c *Capture) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// Wait returns messages of the topic, when at least count messages are captured or the timeout expires.
+// If reading of the topic failed (see Fail), the captured messages are returned with the error.
+func (c *Capture) Wait(topic string, count int, timeout time.Duration) ([]Message, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		c.mutex.Lock()
+		messages, ok := c.messages[topic]
+		err := c.errs[topic]
+		changed := c.changed
+		c.mutex.Unlock()
+
+		if !ok {
+			return nil, fmt.Errorf("topic '%s' is not captured", topic)
+		}
+		if len(messages) >= count {
+			return messages, nil
+		}
+		if err != nil {
+			return messages, err
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return messages, nil
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package messagebus
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// MemoryBus is the in-memory message bus. It's intended for tests of the service, which runs
+// in the same process with Gonkex: the service publishes messages to the bus and subscribes
+// to its topics instead of using the real message broker.
+type MemoryBus struct {
+	mutex       sync.Mutex
+	subscribers map[string][]func(Message)
+	capture     *Capture
+}
+
+// NewMemoryBus creates the empty in-memory message bus.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		subscribers: map[string][]func(Message){},
+	}
+}
+
+func (b *MemoryBus) GetType() string {
+	return "memory"
+}
+
+// Subscribe registers the handler, which is called for every message published to the topic.
+// Handlers are called synchronously by Publish.
+func (b *MemoryBus) Subscribe(topic string, handler func(Message)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.subscribers[topic] = append(b.subscribers[topic], handler)
+}
+
+func (b *MemoryBus) Publish(topic string, messages []Message) error {
+	b.mutex.Lock()
+	capture := b.capture
+	handlers := b.subscribers[topic]
+	b.mutex.Unlock()
+
+	for _, msg := range messages {
+		if capture != nil {
+			capture.Add(topic, msg)
+		}
+		// handlers are called without lock, so they can publish messages too
+		for _, handler := range handlers {
+			handler(msg)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) StartCapture(topics []string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.capture = NewCapture(topics)
+	return nil
+}
+
+func (b *MemoryBus) Captured(topic string, count int, timeout time.Duration) ([]Message, error) {
+	b.mutex.Lock()
+	capture := b.capture
+	b.mutex.Unlock()
+
+	if capture == nil {
+		return nil, errors.New("capture of messages is not started")
+	}
+	return capture.Wait(topic, count, timeout)
+}
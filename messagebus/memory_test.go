@@ -0,0 +1,75 @@
+package messagebus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBus_Captured(t *testing.T) {
+	bus := NewMemoryBus()
+
+	_, err := bus.Captured("events", 1, time.Millisecond)
+	require.EqualError(t, err, "capture of messages is not started")
+
+	require.NoError(t, bus.Publish("events", []Message{{Value: "before capture"}}))
+	require.NoError(t, bus.StartCapture([]string{"events"}))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = bus.Publish("events", []Message{{Key: "1", Value: "first"}})
+		_ = bus.Publish("other", []Message{{Value: "not captured"}})
+		_ = bus.Publish("events", []Message{{Key: "2", Value: "second"}})
+	}()
+
+	messages, err := bus.Captured("events", 2, 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []Message{{Key: "1", Value: "first"}, {Key: "2", Value: "second"}}, messages)
+
+	// timeout expires, all captured messages are returned
+	messages, err = bus.Captured("events", 3, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	_, err = bus.Captured("other", 1, time.Millisecond)
+	require.EqualError(t, err, "topic 'other' is not captured")
+}
+
+func TestMemoryBus_Subscribe(t *testing.T) {
+	bus := NewMemoryBus()
+	require.NoError(t, bus.StartCapture([]string{"replies"}))
+
+	bus.Subscribe("requests", func(msg Message) {
+		_ = bus.Publish("replies", []Message{{Value: "reply to " + msg.Value}})
+	})
+	require.NoError(t, bus.Publish("requests", []Message{{Value: "ping"}}))
+
+	messages, err := bus.Captured("replies", 1, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []Message{{Value: "reply to ping"}}, messages)
+}
+
+func TestCapture_Fail(t *testing.T) {
+	capture := NewCapture([]string{"events"})
+	capture.Add("events", Message{Value: "first"})
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		capture.Fail("events", errors.New("connection lost"))
+		capture.Fail("events", errors.New("second error"))
+		capture.Fail("other", errors.New("not captured"))
+	}()
+
+	start := time.Now()
+	messages, err := capture.Wait("events", 2, 5*time.Second)
+	require.EqualError(t, err, "connection lost")
+	require.Equal(t, []Message{{Value: "first"}}, messages)
+	require.Less(t, time.Since(start), time.Second)
+
+	// enough messages are captured before the error
+	messages, err = capture.Wait("events", 1, time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+}
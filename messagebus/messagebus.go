@@ -0,0 +1,33 @@
+package messagebus
+
+import (
+	"time"
+)
+
+// Message is a message of the message bus topic.
+type Message struct {
+	Key     string
+	Value   string
+	Headers map[string]string
+}
+
+// MessageBusInterface defines a message broker abstraction, which allows to publish messages
+// and to capture messages produced to topics by the tested service.
+type MessageBusInterface interface {
+	// GetType returns the type of message bus being used (e.g., "kafka", "memory").
+	GetType() string
+
+	// Publish sends messages to the topic.
+	// Returns an error if the messages cannot be sent.
+	Publish(topic string, messages []Message) error
+
+	// StartCapture drops previously captured messages and starts capturing of messages
+	// produced to the specified topics.
+	StartCapture(topics []string) error
+
+	// Captured returns messages produced to the topic after the start of capturing.
+	// It waits until at least count messages are captured or the timeout expires,
+	// in the last case all messages captured so far are returned.
+	// Returns an error if reading of the topic failed before enough messages are captured.
+	Captured(topic string, count int, timeout time.Duration) ([]Message, error)
+}
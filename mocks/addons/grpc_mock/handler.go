@@ -0,0 +1,192 @@
+package grpc_mock
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// headers added to every reply by the mock, which make no sense as gRPC metadata
+var skippedHeaders = map[string]bool{
+	"Content-Type":   true,
+	"Content-Length": true,
+	"Date":           true,
+}
+
+// handleStream converts gRPC call into HTTP request for the mock definition
+// and sends the reply of the definition back to the client.
+func (s *server) handleStream(_ interface{}, stream grpc.ServerStream) error {
+	fullMethod, _ := grpc.MethodFromServerStream(stream)
+	method, err := s.findMethod(fullMethod)
+	if err != nil {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return status.Errorf(codes.Unimplemented, "mock doesn't support streaming method %s", fullMethod)
+	}
+
+	types := dynamicpb.NewTypes(s.files)
+	in := dynamicpb.NewMessage(method.Input())
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	body, err := marshalJSON(in, types)
+	if err != nil {
+		return status.Errorf(codes.Internal, "mock can't encode request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(stream.Context(), http.MethodPost, fullMethod, bytes.NewReader(body))
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	for key, values := range md {
+		if strings.HasPrefix(key, ":") {
+			continue
+		}
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if authority := md.Get(":authority"); len(authority) != 0 {
+		req.Host = authority[0]
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := &responseWriter{header: http.Header{}, statusCode: http.StatusOK}
+	s.handler.ServeHTTP(w, req)
+	return w.reply(stream, method, types)
+}
+
+func (s *server) findMethod(fullMethod string) (protoreflect.MethodDescriptor, error) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	pos := strings.LastIndex(name, "/")
+	if pos == -1 {
+		return nil, fmt.Errorf("invalid method %s", fullMethod)
+	}
+
+	desc, err := s.files.FindDescriptorByName(protoreflect.FullName(name[:pos]))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service %s", name[:pos])
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("unknown service %s", name[:pos])
+	}
+	method := service.Methods().ByName(protoreflect.Name(name[pos+1:]))
+	if method == nil {
+		return nil, fmt.Errorf("unknown method %s for service %s", name[pos+1:], name[:pos])
+	}
+	return method, nil
+}
+
+// marshalJSON encodes message as compact JSON (output of protojson is intentionally unstable).
+func marshalJSON(msg *dynamicpb.Message, types *dynamicpb.Types) ([]byte, error) {
+	content, err := protojson.MarshalOptions{Resolver: types}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, content); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// grpcCode converts status code of the mock reply into gRPC status code.
+// Codes of HTTP errors are mapped as described in gRPC documentation (http-grpc-status-mapping).
+func grpcCode(statusCode int) codes.Code {
+	switch {
+	case statusCode >= 0 && statusCode <= 16:
+		return codes.Code(statusCode)
+	case statusCode >= 200 && statusCode < 300:
+		return codes.OK
+	}
+
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// responseWriter collects the reply of the mock definition.
+type responseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	dropped    bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// Hijack is used by dropRequest strategy.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.dropped = true
+	server, client := net.Pipe()
+	_ = client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func (w *responseWriter) reply(stream grpc.ServerStream, method protoreflect.MethodDescriptor,
+	types *dynamicpb.Types) error {
+	if w.dropped {
+		return status.Error(codes.Unavailable, "connection dropped by mock")
+	}
+
+	md := metadata.MD{}
+	for key, values := range w.header {
+		if !skippedHeaders[key] {
+			md.Append(key, values...)
+		}
+	}
+	if len(md) != 0 {
+		if err := stream.SetHeader(md); err != nil {
+			return err
+		}
+	}
+
+	if code := grpcCode(w.statusCode); code != codes.OK {
+		return status.Error(code, strings.TrimSpace(w.body.String()))
+	}
+
+	out := dynamicpb.NewMessage(method.Output())
+	if len(bytes.TrimSpace(w.body.Bytes())) != 0 {
+		err := protojson.UnmarshalOptions{Resolver: types}.Unmarshal(w.body.Bytes(), out)
+		if err != nil {
+			return status.Errorf(codes.Internal, "mock reply is not valid %s: %v", method.Output().FullName(), err)
+		}
+	}
+	return stream.SendMsg(out)
+}
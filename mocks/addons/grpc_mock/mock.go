@@ -0,0 +1,110 @@
+package grpc_mock
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Opts holds configuration of the gRPC mock service.
+type Opts struct {
+	// DescriptorSetFile is a path to the file with serialized FileDescriptorSet
+	// (created by protoc with --descriptor_set_out and --include_imports flags).
+	DescriptorSetFile string
+
+	// Files contains descriptors of mocked services, if DescriptorSetFile is empty.
+	// By default, descriptors of generated code linked into the binary (protoregistry.GlobalFiles) are used.
+	Files *protoregistry.Files
+
+	// ServerOptions are passed to grpc.NewServer.
+	ServerOptions []grpc.ServerOption
+}
+
+// NewServiceMock creates mock of gRPC service, which is configured with the same definitions
+// as HTTP mocks and can be added to mocks.Mocks together with them.
+//
+// Every unary call is passed to the definition as POST request with path /package.Service/Method,
+// JSON-encoded request message as body and metadata as headers.
+// The reply body is decoded as JSON-encoded response message. If status code of the reply
+// is between 0 and 16, it is used as gRPC status code and the body as status message.
+func NewServiceMock(serviceName string, opts *Opts) (*mocks.ServiceMock, error) {
+	if opts == nil {
+		opts = &Opts{}
+	}
+
+	files := opts.Files
+	if opts.DescriptorSetFile != "" {
+		var err error
+		files, err = readDescriptorSet(opts.DescriptorSetFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if files == nil {
+		files = protoregistry.GlobalFiles
+	}
+
+	m := mocks.NewServiceMock(serviceName, nil)
+	m.SetServerFactory(func(_ string, handler http.Handler) mocks.Server {
+		s := &server{
+			files:   files,
+			handler: handler,
+		}
+		serverOptions := append([]grpc.ServerOption{grpc.UnknownServiceHandler(s.handleStream)}, opts.ServerOptions...)
+		s.grpcServer = grpc.NewServer(serverOptions...)
+		return s
+	})
+	return m, nil
+}
+
+func readDescriptorSet(fileName string) (*protoregistry.Files, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read descriptor set: %w", err)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(content, set); err != nil {
+		return nil, fmt.Errorf("decode descriptor set %s: %w", fileName, err)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("load descriptor set %s: %w", fileName, err)
+	}
+	return files, nil
+}
+
+type server struct {
+	grpcServer *grpc.Server
+	files      *protoregistry.Files
+	handler    http.Handler
+}
+
+func (s *server) Serve(ln net.Listener) error {
+	return s.grpcServer.Serve(ln)
+}
+
+func (s *server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,287 @@
+package grpc_mock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// usersFile describes service:
+//
+//	service UserService {
+//	  rpc GetUser(GetUserRequest) returns (User);
+//	  rpc WatchUsers(GetUserRequest) returns (stream User);
+//	}
+var usersFile = &descriptorpb.FileDescriptorProto{
+	Name:    proto.String("users/v1/users.proto"),
+	Package: proto.String("users.v1"),
+	Syntax:  proto.String("proto3"),
+	MessageType: []*descriptorpb.DescriptorProto{
+		{
+			Name: proto.String("GetUserRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+			},
+		},
+		{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			},
+		},
+	},
+	Service: []*descriptorpb.ServiceDescriptorProto{
+		{
+			Name: proto.String("UserService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{
+					Name:       proto.String("GetUser"),
+					InputType:  proto.String(".users.v1.GetUserRequest"),
+					OutputType: proto.String(".users.v1.User"),
+				},
+				{
+					Name:            proto.String("WatchUsers"),
+					InputType:       proto.String(".users.v1.GetUserRequest"),
+					OutputType:      proto.String(".users.v1.User"),
+					ServerStreaming: proto.Bool(true),
+				},
+			},
+		},
+	},
+}
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+}
+
+type testClient struct {
+	conn   *grpc.ClientConn
+	method protoreflect.MethodDescriptor
+}
+
+func startMock(t *testing.T, opts *Opts) (*mocks.Mocks, *testClient) {
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{usersFile},
+	})
+	require.NoError(t, err)
+	if opts.DescriptorSetFile == "" {
+		opts.Files = files
+	}
+
+	mock, err := NewServiceMock("users", opts)
+	require.NoError(t, err)
+	m := mocks.New(mock)
+	require.NoError(t, m.Start())
+	t.Cleanup(m.Shutdown)
+
+	conn, err := grpc.NewClient(mock.ServerAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	desc, err := files.FindDescriptorByName("users.v1.UserService")
+	require.NoError(t, err)
+	method := desc.(protoreflect.ServiceDescriptor).Methods().ByName("GetUser")
+	return m, &testClient{conn: conn, method: method}
+}
+
+// getUser calls GetUser method and returns response encoded as JSON.
+func (c *testClient) getUser(t *testing.T, request string, md metadata.MD) (string, metadata.MD, error) {
+	in := dynamicpb.NewMessage(c.method.Input())
+	require.NoError(t, protojson.Unmarshal([]byte(request), in))
+	out := dynamicpb.NewMessage(c.method.Output())
+
+	var header metadata.MD
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	err := c.conn.Invoke(ctx, "/users.v1.UserService/GetUser", in, out, grpc.Header(&header))
+	if err != nil {
+		return "", header, err
+	}
+	body, err := protojson.Marshal(out)
+	require.NoError(t, err)
+	return string(body), header, nil
+}
+
+func loadDefinition(t *testing.T, m *mocks.Mocks, content string) {
+	m.ResetRunningContext()
+	require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, content))
+}
+
+func Test_Mock_ConstantReply(t *testing.T) {
+	m, client := startMock(t, &Opts{})
+
+	loadDefinition(t, m, `
+users:
+  requestConstraints:
+    - kind: pathMatches
+      path: /users.v1.UserService/GetUser
+    - kind: headerIs
+      header: authorization
+      value: secret
+    - kind: bodyMatchesJSON
+      body: '{"id": "1"}'
+  strategy: constant
+  body: '{"id": "1", "name": "John"}'
+  headers:
+    x-request-id: "42"
+  calls: 1
+`)
+
+	body, header, err := client.getUser(t, `{"id": "1"}`, metadata.Pairs("authorization", "secret"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id": "1", "name": "John"}`, body)
+	require.Equal(t, []string{"42"}, header.Get("x-request-id"))
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_Mock_ConstraintErrors(t *testing.T) {
+	m, client := startMock(t, &Opts{})
+
+	loadDefinition(t, m, `
+users:
+  requestConstraints:
+    - kind: bodyMatchesJSON
+      body: '{"id": "2"}'
+  strategy: constant
+  body: '{}'
+  calls: 2
+`)
+
+	_, _, err := client.getUser(t, `{"id": "1"}`, nil)
+	require.NoError(t, err)
+
+	errs := m.EndRunningContext(false)
+	require.Len(t, errs, 2)
+	require.Contains(t, errs[0].Error(), "mock 'users': request constraint 'bodyMatchesJSON'")
+	require.Contains(t, errs[1].Error(), "number of 'calls' does not match")
+}
+
+func Test_Mock_StatusCodes(t *testing.T) {
+	m, client := startMock(t, &Opts{})
+
+	loadDefinition(t, m, `
+users:
+  strategy: sequence
+  sequence:
+    - strategy: constant
+      statusCode: 5
+      body: user not found
+    - strategy: constant
+      statusCode: 503
+      body: try later
+    - strategy: constant
+      body: '{"unknown": 1}'
+    - strategy: dropRequest
+`)
+
+	expected := []*status.Status{
+		status.New(codes.NotFound, "user not found"),
+		status.New(codes.Unavailable, "try later"),
+		status.New(codes.Internal, ""),
+		status.New(codes.Unavailable, "connection dropped by mock"),
+	}
+	for idx, exp := range expected {
+		_, _, err := client.getUser(t, `{"id": "1"}`, nil)
+		st, ok := status.FromError(err)
+		require.True(t, ok, "call #%d", idx)
+		require.Equal(t, exp.Code(), st.Code(), "call #%d", idx)
+		if exp.Message() != "" {
+			require.Equal(t, exp.Message(), st.Message(), "call #%d", idx)
+		}
+	}
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_Mock_UriVary(t *testing.T) {
+	m, client := startMock(t, &Opts{})
+
+	loadDefinition(t, m, `
+users:
+  strategy: uriVary
+  uris:
+    /users.v1.UserService/GetUser:
+      strategy: constant
+      statusCode: 7
+      body: access denied
+      calls: 1
+    /users.v1.UserService/WatchUsers:
+      strategy: nop
+      calls: 0
+`)
+
+	_, _, err := client.getUser(t, `{"id": "1"}`, nil)
+	require.Equal(t, status.Error(codes.PermissionDenied, "access denied").Error(), err.Error())
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_Mock_UnsupportedMethods(t *testing.T) {
+	_, client := startMock(t, &Opts{})
+
+	err := client.conn.Invoke(context.Background(), "/users.v1.OrderService/GetOrder",
+		dynamicpb.NewMessage(client.method.Input()), dynamicpb.NewMessage(client.method.Output()))
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+	require.Contains(t, err.Error(), "unknown service users.v1.OrderService")
+
+	stream, err := client.conn.NewStream(context.Background(),
+		&grpc.StreamDesc{ServerStreams: true}, "/users.v1.UserService/WatchUsers")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(dynamicpb.NewMessage(client.method.Input())))
+	require.NoError(t, stream.CloseSend())
+	err = stream.RecvMsg(dynamicpb.NewMessage(client.method.Output()))
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+	require.Contains(t, err.Error(), "mock doesn't support streaming method /users.v1.UserService/WatchUsers")
+}
+
+func Test_Mock_DescriptorSetFile(t *testing.T) {
+	content, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{usersFile},
+	})
+	require.NoError(t, err)
+	fileName := filepath.Join(t.TempDir(), "users.protoset")
+	require.NoError(t, os.WriteFile(fileName, content, 0o600))
+
+	m, client := startMock(t, &Opts{DescriptorSetFile: fileName})
+	loadDefinition(t, m, `
+users:
+  strategy: constant
+  body: '{"name": "John"}'
+`)
+
+	body, _, err := client.getUser(t, `{}`, nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "John"}`, body)
+}
+
+func Test_NewServiceMock_InvalidDescriptorSet(t *testing.T) {
+	_, err := NewServiceMock("users", &Opts{DescriptorSetFile: "not-exists.protoset"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "read descriptor set:")
+
+	_, err = NewServiceMock("users", &Opts{Files: protoregistry.GlobalFiles})
+	require.NoError(t, err)
+}
@@ -0,0 +1,369 @@
+package nats_mock
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const headerLine = "NATS/1.0"
+
+// headers added to every reply by the mock, which make no sense as NATS headers
+var skippedHeaders = map[string]bool{
+	"Content-Type":   true,
+	"Content-Length": true,
+	"Date":           true,
+}
+
+type subscription struct {
+	subject   string
+	queue     string
+	sid       string
+	max       int
+	delivered int
+}
+
+// conn serves the client connection. Only core NATS protocol is supported
+// (no JetStream, authentication and TLS).
+type conn struct {
+	server  *server
+	netConn net.Conn
+	reader  *bufio.Reader
+
+	writeMutex sync.Mutex
+	writer     *bufio.Writer
+
+	// subscriptions are protected by mutex of the server
+	subscriptions map[string]*subscription
+
+	verbose      bool
+	headers      bool
+	noResponders bool
+}
+
+func newConn(s *server, netConn net.Conn) *conn {
+	return &conn{
+		server:        s,
+		netConn:       netConn,
+		reader:        bufio.NewReader(netConn),
+		writer:        bufio.NewWriter(netConn),
+		subscriptions: map[string]*subscription{},
+	}
+}
+
+func (c *conn) serve() {
+	defer func() {
+		c.server.removeConn(c)
+		_ = c.netConn.Close()
+	}()
+
+	if err := c.sendInfo(); err != nil {
+		return
+	}
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			continue
+		}
+
+		op, args, _ := strings.Cut(line, " ")
+		if err := c.process(strings.ToUpper(op), strings.Fields(args)); err != nil {
+			c.send("-ERR '%s'\r\n", err.Error())
+			return
+		}
+	}
+}
+
+func (c *conn) process(op string, args []string) error {
+	switch op {
+	case "CONNECT":
+		return c.processConnect(strings.Join(args, " "))
+	case "PING":
+		c.send("PONG\r\n")
+		return nil
+	case "PONG":
+		return nil
+	case "SUB":
+		return c.processSub(args)
+	case "UNSUB":
+		return c.processUnsub(args)
+	case "PUB":
+		return c.processPub(args, false)
+	case "HPUB":
+		return c.processPub(args, true)
+	default:
+		return fmt.Errorf("Unknown Protocol Operation")
+	}
+}
+
+func (c *conn) processConnect(options string) error {
+	var opts struct {
+		Verbose      bool `json:"verbose"`
+		Headers      bool `json:"headers"`
+		NoResponders bool `json:"no_responders"`
+	}
+	if err := json.Unmarshal([]byte(options), &opts); err != nil {
+		return fmt.Errorf("Invalid CONNECT options")
+	}
+	c.verbose = opts.Verbose
+	c.headers = opts.Headers
+	c.noResponders = opts.NoResponders && opts.Headers
+	c.ok()
+	return nil
+}
+
+// processSub handles SUB <subject> [queue group] <sid>
+func (c *conn) processSub(args []string) error {
+	sub := &subscription{}
+	switch len(args) {
+	case 2:
+		sub.subject, sub.sid = args[0], args[1]
+	case 3:
+		sub.subject, sub.queue, sub.sid = args[0], args[1], args[2]
+	default:
+		return fmt.Errorf("Invalid Subscription")
+	}
+
+	c.server.mutex.Lock()
+	c.subscriptions[sub.sid] = sub
+	c.server.mutex.Unlock()
+	c.ok()
+	return nil
+}
+
+// processUnsub handles UNSUB <sid> [max_msgs]
+func (c *conn) processUnsub(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("Invalid Unsubscribe")
+	}
+	max := 0
+	if len(args) == 2 {
+		var err error
+		if max, err = strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("Invalid Unsubscribe")
+		}
+	}
+
+	c.server.mutex.Lock()
+	if sub, ok := c.subscriptions[args[0]]; ok {
+		if max == 0 || sub.delivered >= max {
+			delete(c.subscriptions, args[0])
+		} else {
+			sub.max = max
+		}
+	}
+	c.server.mutex.Unlock()
+	c.ok()
+	return nil
+}
+
+// processPub handles PUB <subject> [reply-to] <#bytes>
+// and HPUB <subject> [reply-to] <#header bytes> <#total bytes>
+func (c *conn) processPub(args []string, withHeaders bool) error {
+	sizes := 1
+	if withHeaders {
+		sizes = 2
+	}
+	if len(args) != sizes+1 && len(args) != sizes+2 {
+		return fmt.Errorf("Invalid Publish")
+	}
+	subject, reply := args[0], ""
+	if len(args) == sizes+2 {
+		reply = args[1]
+	}
+
+	headerSize := 0
+	totalSize, err := strconv.Atoi(args[len(args)-1])
+	if err == nil && withHeaders {
+		headerSize, err = strconv.Atoi(args[len(args)-2])
+	}
+	if err != nil || totalSize < headerSize || headerSize < 0 {
+		return fmt.Errorf("Invalid Publish")
+	}
+	if totalSize > maxPayload {
+		return fmt.Errorf("Maximum Payload Violation")
+	}
+
+	data := make([]byte, totalSize+2)
+	if _, err := io.ReadFull(c.reader, data); err != nil {
+		return err
+	}
+	header, payload := data[:headerSize], data[headerSize:totalSize]
+	c.ok()
+
+	req, err := newRequest(c.server.name, subject, header, payload)
+	if err != nil {
+		return err
+	}
+	w := &responseWriter{header: http.Header{}, statusCode: http.StatusOK}
+	c.server.handler.ServeHTTP(w, req)
+
+	c.server.deliver(subject, reply, header, payload)
+	if reply != "" {
+		c.sendReply(reply, w)
+	}
+	return nil
+}
+
+// newRequest converts the published message into the request for the mock definition.
+func newRequest(host, subject string, header, payload []byte) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, "/"+subject, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Subject")
+	}
+	req.Host = host
+	if len(header) == 0 {
+		return req, nil
+	}
+
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(header)))
+	if line, err := r.ReadLine(); err != nil || !strings.HasPrefix(line, headerLine) {
+		return nil, fmt.Errorf("Invalid Headers")
+	}
+	mimeHeader, err := r.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("Invalid Headers")
+	}
+	for key, values := range mimeHeader {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return req, nil
+}
+
+// sendReply sends the reply of the mock definition to the reply subject.
+func (c *conn) sendReply(reply string, w *responseWriter) {
+	if w.dropped {
+		// the request stays without reply
+		return
+	}
+	if w.statusCode == http.StatusServiceUnavailable && c.noResponders {
+		// emulate absence of responders, the client gets error immediately
+		c.server.deliver(reply, "", []byte(headerLine+" 503\r\n\r\n"), nil)
+		return
+	}
+
+	var header []byte
+	if c.headers {
+		buf := &bytes.Buffer{}
+		for key, values := range w.header {
+			if skippedHeaders[key] {
+				continue
+			}
+			for _, value := range values {
+				fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+			}
+		}
+		if buf.Len() != 0 {
+			header = append([]byte(headerLine+"\r\n"), buf.Bytes()...)
+			header = append(header, "\r\n"...)
+		}
+	}
+	c.server.deliver(reply, "", header, w.body.Bytes())
+}
+
+// matchSubscriptions returns subscriptions of the subject and counts deliveries.
+// Must be called with locked mutex of the server.
+func (c *conn) matchSubscriptions(subject string) []*subscription {
+	var res []*subscription
+	for sid, sub := range c.subscriptions {
+		if !subjectMatches(sub.subject, subject) {
+			continue
+		}
+		sub.delivered++
+		if sub.max != 0 && sub.delivered >= sub.max {
+			delete(c.subscriptions, sid)
+		}
+		res = append(res, sub)
+	}
+	return res
+}
+
+func (c *conn) sendInfo() error {
+	host, portValue, _ := net.SplitHostPort(c.netConn.LocalAddr().String())
+	port, _ := strconv.Atoi(portValue)
+	info, err := json.Marshal(map[string]interface{}{
+		"server_id":   "GONKEX",
+		"server_name": c.server.name,
+		"version":     "2.10.0",
+		"proto":       1,
+		"host":        host,
+		"port":        port,
+		"headers":     true,
+		"max_payload": maxPayload,
+	})
+	if err != nil {
+		return err
+	}
+	return c.send("INFO %s\r\n", info)
+}
+
+// sendMsg sends MSG (or HMSG if the message has headers) to the client.
+func (c *conn) sendMsg(subject, sid, reply string, header, payload []byte) {
+	if reply != "" {
+		reply += " "
+	}
+	if len(header) != 0 && c.headers {
+		_ = c.send("HMSG %s %s %s%d %d\r\n%s%s\r\n", subject, sid, reply,
+			len(header), len(header)+len(payload), header, payload)
+		return
+	}
+	_ = c.send("MSG %s %s %s%d\r\n%s\r\n", subject, sid, reply, len(payload), payload)
+}
+
+func (c *conn) ok() {
+	if c.verbose {
+		_ = c.send("+OK\r\n")
+	}
+}
+
+func (c *conn) send(format string, args ...interface{}) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if _, err := fmt.Fprintf(c.writer, format, args...); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+// responseWriter collects the reply of the mock definition.
+type responseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	dropped    bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// Hijack is used by dropRequest strategy.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.dropped = true
+	server, client := net.Pipe()
+	_ = client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
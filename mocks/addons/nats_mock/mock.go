@@ -0,0 +1,149 @@
+package nats_mock
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lansfy/gonkex/mocks"
+)
+
+// maxPayload is the maximum size of the message announced to clients.
+const maxPayload = 1024 * 1024
+
+// NewServiceMock creates mock of NATS server, which is configured with the same definitions
+// as HTTP mocks and can be added to mocks.Mocks together with them.
+//
+// Every message published by clients is passed to the definition as POST request with path
+// /<subject>, the payload as body and NATS headers as headers. If the message has a reply subject,
+// the body of the reply is sent to it, so the mock answers requests. The messages are also
+// delivered to subscribers of the subject, as the real server does.
+func NewServiceMock(serviceName string) *mocks.ServiceMock {
+	m := mocks.NewServiceMock(serviceName, nil)
+	m.SetServerFactory(func(_ string, handler http.Handler) mocks.Server {
+		return &server{
+			name:    m.ServiceName,
+			handler: handler,
+			conns:   map[*conn]struct{}{},
+		}
+	})
+	return m
+}
+
+type server struct {
+	name    string
+	handler http.Handler
+
+	mutex    sync.Mutex
+	listener net.Listener
+	conns    map[*conn]struct{}
+	closed   bool
+}
+
+func (s *server) Serve(ln net.Listener) error {
+	s.mutex.Lock()
+	s.listener = ln
+	s.mutex.Unlock()
+
+	for {
+		netConn, err := ln.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+			if closed {
+				return http.ErrServerClosed
+			}
+			return err
+		}
+
+		c := newConn(s, netConn)
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			_ = netConn.Close()
+			return http.ErrServerClosed
+		}
+		s.conns[c] = struct{}{}
+		s.mutex.Unlock()
+
+		go c.serve()
+	}
+}
+
+// Shutdown closes the listener and all client connections.
+func (s *server) Shutdown(_ context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for c := range s.conns {
+		_ = c.netConn.Close()
+	}
+	if errors.Is(err, net.ErrClosed) {
+		err = nil
+	}
+	return err
+}
+
+func (s *server) removeConn(c *conn) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.conns, c)
+}
+
+// deliver sends the message to all subscribers of the subject. Only one subscriber
+// of every queue group receives the message.
+func (s *server) deliver(subject, reply string, header, payload []byte) {
+	type target struct {
+		c   *conn
+		sid string
+	}
+
+	s.mutex.Lock()
+	var targets []target
+	queues := map[string]bool{}
+	for c := range s.conns {
+		for _, sub := range c.matchSubscriptions(subject) {
+			if sub.queue != "" {
+				if queues[sub.queue] {
+					continue
+				}
+				queues[sub.queue] = true
+			}
+			targets = append(targets, target{c, sub.sid})
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, t := range targets {
+		t.c.sendMsg(subject, t.sid, reply, header, payload)
+	}
+}
+
+// subjectMatches checks the subject against the subscription subject, which can contain
+// wildcards: '*' matches one token and '>' matches one or more tail tokens.
+func subjectMatches(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
@@ -0,0 +1,228 @@
+package nats_mock
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func startMock(t *testing.T) (*mocks.Mocks, *testClient) {
+	t.Helper()
+
+	mock := NewServiceMock("broker")
+	m := mocks.New(mock)
+	require.NoError(t, m.Start())
+	t.Cleanup(m.Shutdown)
+
+	return m, connect(t, mock.ServerAddr())
+}
+
+func connect(t *testing.T, addr string) *testClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	c := &testClient{conn: conn, reader: bufio.NewReader(conn)}
+	require.True(t, strings.HasPrefix(c.readLine(t), "INFO {"))
+	c.send(t, `CONNECT {"verbose":false,"headers":true}`+"\r\n")
+	c.flush(t)
+	return c
+}
+
+func (c *testClient) send(t *testing.T, data string) {
+	_, err := io.WriteString(c.conn, data)
+	require.NoError(t, err)
+}
+
+// flush waits until all previous commands are processed by the server.
+func (c *testClient) flush(t *testing.T) {
+	c.send(t, "PING\r\n")
+	require.Equal(t, "PONG", c.readLine(t))
+}
+
+func (c *testClient) readLine(t *testing.T) string {
+	line, err := c.reader.ReadString('\n')
+	require.NoError(t, err)
+	return strings.TrimRight(line, "\r\n")
+}
+
+// readMsg reads MSG or HMSG and returns header line of the message, headers and payload.
+func (c *testClient) readMsg(t *testing.T) (string, string, string) {
+	line := c.readLine(t)
+	fields := strings.Fields(line)
+	require.True(t, fields[0] == "MSG" || fields[0] == "HMSG", line)
+
+	size, err := strconv.Atoi(fields[len(fields)-1])
+	require.NoError(t, err)
+	headerSize := 0
+	if fields[0] == "HMSG" {
+		headerSize, err = strconv.Atoi(fields[len(fields)-2])
+		require.NoError(t, err)
+	}
+
+	data := make([]byte, size+2)
+	_, err = io.ReadFull(c.reader, data)
+	require.NoError(t, err)
+	return line, string(data[:headerSize]), string(data[headerSize:size])
+}
+
+func loadDefinition(t *testing.T, m *mocks.Mocks, content string) {
+	m.ResetRunningContext()
+	require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, content))
+}
+
+func Test_Mock_CapturePublish(t *testing.T) {
+	m, client := startMock(t)
+
+	loadDefinition(t, m, `
+broker:
+  requestConstraints:
+    - kind: pathMatches
+      path: /orders.created
+    - kind: headerIs
+      header: X-Trace
+      value: "42"
+    - kind: bodyMatchesJSON
+      body: '{"id": 1}'
+  strategy: constant
+  body: ''
+  calls: 1
+`)
+
+	header := "NATS/1.0\r\nX-Trace: 42\r\n\r\n"
+	payload := `{"id": 1}`
+	client.send(t, "HPUB orders.created "+strconv.Itoa(len(header))+" "+
+		strconv.Itoa(len(header)+len(payload))+"\r\n"+header+payload+"\r\n")
+	client.flush(t)
+
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_Mock_ConstraintErrors(t *testing.T) {
+	m, client := startMock(t)
+
+	loadDefinition(t, m, `
+broker:
+  strategy: uriVary
+  uris:
+    /orders.created:
+      requestConstraints:
+        - kind: bodyMatchesJSON
+          body: '{"id": 2}'
+      strategy: constant
+      body: ''
+      calls: 1
+    /orders.deleted:
+      strategy: constant
+      body: ''
+      calls: 1
+`)
+
+	client.send(t, "PUB orders.created 9\r\n{\"id\": 1}\r\n")
+	client.send(t, "PUB orders.updated 2\r\n{}\r\n")
+	client.flush(t)
+
+	errs := m.EndRunningContext(false)
+	require.Len(t, errs, 3)
+	require.Contains(t, errs[0].Error(), "request constraint 'bodyMatchesJSON'")
+	require.Contains(t, errs[1].Error(), "/orders.updated")
+	require.Contains(t, errs[2].Error(), "number of 'calls' does not match")
+}
+
+func Test_Mock_Reply(t *testing.T) {
+	m, client := startMock(t)
+
+	loadDefinition(t, m, `
+broker:
+  strategy: sequence
+  sequence:
+    - strategy: constant
+      body: '{"status": "ok"}'
+      headers:
+        X-Version: "2"
+    - strategy: dropRequest
+`)
+
+	client.send(t, "SUB _INBOX.reply 1\r\n")
+	client.send(t, "PUB users.get _INBOX.reply 1\r\n1\r\n")
+	line, header, payload := client.readMsg(t)
+	require.Equal(t, "HMSG _INBOX.reply 1 26 42", line)
+	require.Equal(t, "NATS/1.0\r\nX-Version: 2\r\n\r\n", header)
+	require.Equal(t, `{"status": "ok"}`, payload)
+
+	// dropped request has no reply
+	client.send(t, "PUB users.get _INBOX.reply 1\r\n2\r\n")
+	client.flush(t)
+
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_Mock_Subscribers(t *testing.T) {
+	m, client := startMock(t)
+	subscriber := connect(t, m.Service("broker").ServerAddr())
+
+	loadDefinition(t, m, `
+broker:
+  strategy: nop
+  calls: 2
+`)
+
+	subscriber.send(t, "SUB orders.* 1\r\n")
+	subscriber.send(t, "SUB orders.> 2\r\n")
+	subscriber.send(t, "UNSUB 2 1\r\n")
+	subscriber.flush(t)
+
+	client.send(t, "PUB orders.created 5\r\nfirst\r\n")
+	line, _, payload := subscriber.readMsg(t)
+	second, _, _ := subscriber.readMsg(t)
+	require.ElementsMatch(t, []string{"MSG orders.created 1 5", "MSG orders.created 2 5"}, []string{line, second})
+	require.Equal(t, "first", payload)
+
+	client.send(t, "PUB orders.created 6\r\nsecond\r\n")
+	line, _, payload = subscriber.readMsg(t)
+	require.Equal(t, "MSG orders.created 1 6", line)
+	require.Equal(t, "second", payload)
+	subscriber.flush(t)
+
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_SubjectMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{"orders", "orders", true},
+		{"orders", "orders.created", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*.eu", "orders.created.eu", true},
+		{"orders.>", "orders.created.eu", true},
+		{"orders.>", "orders", false},
+		{">", "orders", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.subject, func(t *testing.T) {
+			require.Equal(t, tt.want, subjectMatches(tt.pattern, tt.subject))
+		})
+	}
+}
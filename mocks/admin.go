@@ -0,0 +1,232 @@
+package mocks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AdminPrefix is the path prefix of the admin API, it's placed under the default prefix of helper endpoints.
+const AdminPrefix = "/gonkex/mocks/"
+
+// AdminService describes the mock service in the admin API.
+type AdminService struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// NewAdminHandler creates handler of the admin API, which allows to inspect and reconfigure
+// mock services at runtime, e.g. from test harnesses written in other languages:
+//
+//	GET  /gonkex/mocks/services                 - list of services with their addresses
+//	GET  /gonkex/mocks/services/<name>/requests - requests received by the service since the last reset
+//	POST /gonkex/mocks/definitions              - loads YAML definitions (map of service name to definition),
+//	                                              the record parameter of proxy strategy is not allowed
+//	POST /gonkex/mocks/reset                    - restores default definitions and clears requests of all services
+//
+// Errors are returned as JSON object with the "error" field.
+func NewAdminHandler(m *Mocks, loader Loader) http.Handler {
+	return &adminHandler{mocks: m, loader: loader}
+}
+
+type adminHandler struct {
+	mocks  *Mocks
+	loader Loader
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, AdminPrefix) {
+		writeAdminError(w, http.StatusNotFound, fmt.Errorf("path '%s' not found", r.URL.Path))
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, AdminPrefix), "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "services":
+		if checkAdminMethod(w, r, http.MethodGet) {
+			writeJSON(w, http.StatusOK, h.services())
+		}
+	case len(parts) == 3 && parts[0] == "services" && parts[2] == "requests":
+		if checkAdminMethod(w, r, http.MethodGet) {
+			h.requests(w, parts[1])
+		}
+	case len(parts) == 1 && parts[0] == "definitions":
+		if checkAdminMethod(w, r, http.MethodPost) {
+			h.load(w, r)
+		}
+	case len(parts) == 1 && parts[0] == "reset":
+		if checkAdminMethod(w, r, http.MethodPost) {
+			h.mocks.ResetDefinitions()
+			h.mocks.ResetRunningContext()
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		writeAdminError(w, http.StatusNotFound, fmt.Errorf("path '%s' not found", r.URL.Path))
+	}
+}
+
+func (h *adminHandler) services() []AdminService {
+	names := h.mocks.GetNames()
+	sort.Strings(names)
+
+	services := []AdminService{}
+	for _, name := range names {
+		service := AdminService{Name: name}
+		if s := h.mocks.Service(name); s.IsStarted() {
+			service.Address = s.ServerAddr()
+		}
+		services = append(services, service)
+	}
+	return services
+}
+
+func (h *adminHandler) requests(w http.ResponseWriter, serviceName string) {
+	requests, err := h.mocks.Requests(serviceName)
+	if err != nil {
+		writeAdminError(w, http.StatusNotFound, err)
+		return
+	}
+
+	items := []interface{}{}
+	for i := range requests {
+		items = append(items, requests[i].ToMap())
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *adminHandler) load(w http.ResponseWriter, r *http.Request) {
+	if h.loader == nil {
+		writeAdminError(w, http.StatusNotImplemented, errors.New("loader of definitions is not configured"))
+		return
+	}
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	var rawDef map[string]interface{}
+	if err := yaml.Unmarshal(content, &rawDef); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	// recording allows clients of the API to write arbitrary files
+	if hasRecording(rawDef) {
+		writeAdminError(w, http.StatusForbidden, errors.New("'record' of proxy strategy is not allowed in definitions loaded by admin API"))
+		return
+	}
+	if err := h.loader.LoadRawDefinition(h.mocks, rawDef); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// hasRecording returns true if any proxy strategy nested in the definition records replies to the file.
+func hasRecording(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, ok := v["record"]; ok && v["strategy"] == "proxy" {
+			return true
+		}
+		for _, item := range v {
+			if hasRecording(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if hasRecording(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkAdminMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not supported for path '%s'", r.Method, r.URL.Path))
+	return false
+}
+
+func writeAdminError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, map[string]string{"error": err.Error()})
+}
+
+// adminServer is HTTP server of the admin API.
+type adminServer struct {
+	mutex    sync.Mutex
+	server   *http.Server
+	listener net.Listener
+}
+
+// AdminServerOpts contains options of the admin server.
+type AdminServerOpts struct {
+	// AllowRemote allows to listen on addresses other than loopback ones.
+	// The admin API has no authentication and loaded definitions can read local files
+	// (e.g. with file strategy), so it must not be exposed to untrusted networks.
+	AllowRemote bool
+}
+
+// StartAdminServer starts HTTP server with the admin API (see NewAdminHandler) on the specified address.
+// If the address has zero port, the random port is used, see AdminServerAddr.
+// The loader is used to load definitions; if it is nil, the loading is not supported.
+// Only loopback addresses are accepted, unless AllowRemote option is set.
+func (m *Mocks) StartAdminServer(addr string, loader Loader, opts *AdminServerOpts) error {
+	m.admin.mutex.Lock()
+	defer m.admin.mutex.Unlock()
+
+	if m.admin.listener != nil {
+		return errors.New("admin server is already started")
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if tcpAddr, ok := ln.Addr().(*net.TCPAddr); ok && !tcpAddr.IP.IsLoopback() && (opts == nil || !opts.AllowRemote) {
+		_ = ln.Close()
+		return fmt.Errorf("address '%s' is not loopback, admin API can be exposed to other hosts only explicitly", addr)
+	}
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: NewAdminHandler(m, loader),
+	}
+	m.admin.server = server
+	m.admin.listener = ln
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	return nil
+}
+
+// AdminServerAddr returns the actual address of the admin server or empty string if the server is not started.
+func (m *Mocks) AdminServerAddr() string {
+	m.admin.mutex.Lock()
+	defer m.admin.mutex.Unlock()
+
+	if m.admin.listener == nil {
+		return ""
+	}
+	return m.admin.listener.Addr().String()
+}
+
+func (m *Mocks) shutdownAdminServer() *http.Server {
+	m.admin.mutex.Lock()
+	defer m.admin.mutex.Unlock()
+
+	server := m.admin.server
+	m.admin.server = nil
+	m.admin.listener = nil
+	return server
+}
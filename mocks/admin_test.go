@@ -0,0 +1,174 @@
+package mocks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lansfy/gonkex/endpoint"
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AdminServer(t *testing.T) {
+	require.True(t, strings.HasPrefix(mocks.AdminPrefix, endpoint.DefaultPrefix))
+
+	m := mocks.NewNop("orders", "payments")
+	require.NoError(t, m.Start())
+	require.NoError(t, m.StartAdminServer("localhost:0", mocks.NewYamlLoader(nil), nil))
+	defer m.Shutdown()
+
+	admin := "http://" + m.AdminServerAddr() + mocks.AdminPrefix
+	orders := "http://" + m.Service("orders").ServerAddr()
+
+	code, body := doRequest(t, http.MethodGet, admin+"services", "")
+	require.Equal(t, http.StatusOK, code)
+	var services []mocks.AdminService
+	require.NoError(t, json.Unmarshal([]byte(body), &services))
+	require.Equal(t, []mocks.AdminService{
+		{Name: "orders", Address: m.Service("orders").ServerAddr()},
+		{Name: "payments", Address: m.Service("payments").ServerAddr()},
+	}, services)
+
+	// load definition and call the mock
+	code, body = doRequest(t, http.MethodPost, admin+"definitions", `
+orders:
+  strategy: constant
+  body: '{"id": 1}'
+`)
+	require.Equal(t, http.StatusNoContent, code, body)
+
+	code, body = doRequest(t, http.MethodPost, orders+"/orders?limit=1", `{"name": "book"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, `{"id": 1}`, body)
+
+	code, body = doRequest(t, http.MethodGet, admin+"services/orders/requests", "")
+	require.Equal(t, http.StatusOK, code)
+	var requests []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &requests))
+	require.Len(t, requests, 1)
+	require.Equal(t, "POST", requests[0]["method"])
+	require.Equal(t, "/orders", requests[0]["path"])
+	require.Equal(t, "limit=1", requests[0]["query"])
+	require.Equal(t, map[string]interface{}{"name": "book"}, requests[0]["body"])
+
+	// reset restores default definition and clears requests
+	code, _ = doRequest(t, http.MethodPost, admin+"reset", "")
+	require.Equal(t, http.StatusNoContent, code)
+
+	code, body = doRequest(t, http.MethodGet, admin+"services/orders/requests", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "[]", body)
+
+	code, body = doRequest(t, http.MethodGet, orders+"/orders", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "", body)
+	require.Len(t, m.EndRunningContext(false), 1)
+}
+
+func Test_AdminServer_Errors(t *testing.T) {
+	m := mocks.NewNop("orders")
+	require.NoError(t, m.StartAdminServer("localhost:0", nil, nil))
+	defer m.Shutdown()
+	require.EqualError(t, m.StartAdminServer("localhost:0", nil, nil), "admin server is already started")
+
+	admin := "http://" + m.AdminServerAddr() + mocks.AdminPrefix
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{
+			method: http.MethodGet,
+			path:   "services",
+			code:   http.StatusOK,
+			body:   `[{"name":"orders","address":""}]`,
+		},
+		{
+			method: http.MethodPost,
+			path:   "services",
+			code:   http.StatusMethodNotAllowed,
+			body:   `{"error":"method POST is not supported for path '/gonkex/mocks/services'"}`,
+		},
+		{
+			method: http.MethodGet,
+			path:   "services/unknown/requests",
+			code:   http.StatusNotFound,
+			body:   `{"error":"unknown mock name 'unknown'"}`,
+		},
+		{
+			method: http.MethodPost,
+			path:   "definitions",
+			code:   http.StatusNotImplemented,
+			body:   `{"error":"loader of definitions is not configured"}`,
+		},
+		{
+			method: http.MethodGet,
+			path:   "unknown",
+			code:   http.StatusNotFound,
+			body:   `{"error":"path '/gonkex/mocks/unknown' not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			code, body := doRequest(t, tt.method, admin+tt.path, "")
+			require.Equal(t, tt.code, code)
+			require.Equal(t, tt.body, body)
+		})
+	}
+
+	m.Shutdown()
+	require.Equal(t, "", m.AdminServerAddr())
+}
+
+func Test_AdminHandler_LoadErrors(t *testing.T) {
+	m := mocks.NewNop("orders")
+	require.NoError(t, m.StartAdminServer("localhost:0", mocks.NewYamlLoader(nil), nil))
+	defer m.Shutdown()
+
+	admin := "http://" + m.AdminServerAddr() + mocks.AdminPrefix
+	code, body := doRequest(t, http.MethodPost, admin+"definitions", `
+payments:
+  strategy: constant
+  body: '{}'
+`)
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, `{"error":"unknown mock name 'payments'"}`, body)
+}
+
+func Test_AdminHandler_Record(t *testing.T) {
+	m := mocks.NewNop("orders")
+	require.NoError(t, m.StartAdminServer("localhost:0", mocks.NewYamlLoader(nil), nil))
+	defer m.Shutdown()
+
+	recordFile := filepath.Join(t.TempDir(), "orders.yaml")
+	admin := "http://" + m.AdminServerAddr() + mocks.AdminPrefix
+	code, body := doRequest(t, http.MethodPost, admin+"definitions", `
+orders:
+  strategy: sequence
+  sequence:
+    - strategy: proxy
+      upstream: http://localhost:1
+      record: `+recordFile+`
+`)
+	require.Equal(t, http.StatusForbidden, code)
+	require.Equal(t, `{"error":"'record' of proxy strategy is not allowed in definitions loaded by admin API"}`, body)
+	require.NoFileExists(t, recordFile)
+}
+
+func Test_AdminServer_Remote(t *testing.T) {
+	m := mocks.NewNop("orders")
+	defer m.Shutdown()
+
+	err := m.StartAdminServer(":0", nil, nil)
+	require.EqualError(t, err, "address ':0' is not loopback, admin API can be exposed to other hosts only explicitly")
+	require.Equal(t, "", m.AdminServerAddr())
+
+	require.NoError(t, m.StartAdminServer(":0", nil, &mocks.AdminServerOpts{AllowRemote: true}))
+	require.NotEqual(t, "", m.AdminServerAddr())
+}
@@ -22,6 +22,7 @@ type Definition struct {
 	callsConstraint    int
 	order              *orderChecker
 	orderValue         int
+	faults             *faults
 }
 
 func NewDefinition(path string, constraints []verifier, strategy ReplyStrategy, callsConstraint int, orderValue int) *Definition {
@@ -39,6 +40,7 @@ func (d *Definition) Execute(w http.ResponseWriter, r *http.Request) []error {
 
 	d.mutex.Lock()
 	d.calls++
+	call := d.calls
 	d.mutex.Unlock()
 
 	if d.order != nil {
@@ -49,6 +51,9 @@ func (d *Definition) Execute(w http.ResponseWriter, r *http.Request) []error {
 	if d.replyStrategy != nil {
 		errs = append(errs, d.replyStrategy.HandleRequest(w, r)...)
 	}
+	if d.faults != nil {
+		d.faults.apply(w, call)
+	}
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -128,9 +133,14 @@ func verifyRequestConstraints(requestConstraints []verifier, r *http.Request) []
 func (d *Definition) ExecuteWithoutVerifying(w http.ResponseWriter, r *http.Request) []error {
 	d.mutex.Lock()
 	d.calls++
+	call := d.calls
 	d.mutex.Unlock()
 	if d.replyStrategy == nil {
 		return []error{errors.New("reply strategy undefined")}
 	}
-	return d.replyStrategy.HandleRequest(w, r)
+	errs := d.replyStrategy.HandleRequest(w, r)
+	if d.faults != nil {
+		d.faults.apply(w, call)
+	}
+	return errs
 }
@@ -0,0 +1,310 @@
+package mocks
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	faultDropRequest       = "dropRequest"
+	faultTruncateBody      = "truncateBody"
+	faultSlowBody          = "slowBody"
+	faultResetAfterHeaders = "resetAfterHeaders"
+	faultMalformedChunked  = "malformedChunked"
+
+	defaultBytePause = 10 * time.Millisecond
+)
+
+var faultKinds = []string{
+	faultDropRequest,
+	faultTruncateBody,
+	faultSlowBody,
+	faultResetAfterHeaders,
+	faultMalformedChunked,
+}
+
+// faults describes failures injected into replies of the definition.
+type faults struct {
+	kind        string
+	everyNth    int
+	probability float64
+	latencyMin  time.Duration
+	latencyMax  time.Duration
+	bodyBytes   int
+	bytePause   time.Duration
+
+	mutex sync.Mutex
+	rnd   *rand.Rand
+}
+
+func loadFaults(def map[string]interface{}) (*faults, error) {
+	v, ok := def["faults"]
+	if !ok {
+		return nil, nil
+	}
+
+	wrap := func(err error) error {
+		return fmt.Errorf("section 'faults': %w", err)
+	}
+
+	fDef, err := loadStringMap(v, "faults")
+	if err != nil {
+		return nil, wrap(err)
+	}
+	f, err := loadFaultsParams(fDef)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return f, nil
+}
+
+func loadFaultsParams(def map[string]interface{}) (*faults, error) {
+	allowedKeys := []string{"kind", "everyNth", "probability", "latency", "bodyBytes", "bytePause"}
+	if err := validateMapKeys(def, allowedKeys); err != nil {
+		return nil, err
+	}
+
+	f := &faults{
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec // no need for cryptographic randomness
+	}
+
+	var err error
+	f.kind, err = getOptionalStringKey(def, "kind", false)
+	if err != nil {
+		return nil, err
+	}
+	if f.kind != "" && !isKnownFaultKind(f.kind) {
+		return nil, fmt.Errorf("unknown fault kind '%s' (allowed only %v)", f.kind, faultKinds)
+	}
+	if f.kind == "" && !hasKey(def, "latency") {
+		return nil, errors.New("'kind' or 'latency' key required")
+	}
+
+	f.everyNth, err = getOptionalIntKey(def, "everyNth", 0)
+	if err != nil {
+		return nil, err
+	}
+	f.probability, err = getOptionalProbabilityKey(def, "probability")
+	if err != nil {
+		return nil, err
+	}
+	f.latencyMin, f.latencyMax, err = loadLatency(def)
+	if err != nil {
+		return nil, err
+	}
+	f.bodyBytes, err = getOptionalIntKey(def, "bodyBytes", -1)
+	if err != nil {
+		return nil, err
+	}
+	f.bytePause, err = getOptionalDurationKey(def, "bytePause")
+	if err != nil {
+		return nil, err
+	}
+	if f.bytePause == 0 {
+		f.bytePause = defaultBytePause
+	}
+	return f, nil
+}
+
+func isKnownFaultKind(kind string) bool {
+	for _, k := range faultKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func getOptionalProbabilityKey(def map[string]interface{}, name string) (float64, error) {
+	v, ok := def[name]
+	if !ok {
+		return 1, nil
+	}
+	var value float64
+	switch v := v.(type) {
+	case int:
+		value = float64(v)
+	case float64:
+		value = v
+	default:
+		return 0, fmt.Errorf("value for key '%s' cannot be converted to number", name)
+	}
+	if value < 0 || value > 1 {
+		return 0, fmt.Errorf("value for the key '%s' must be between 0 and 1", name)
+	}
+	return value, nil
+}
+
+// loadLatency reads latency as range (min, max) or as value with jitter (value, jitter).
+func loadLatency(def map[string]interface{}) (time.Duration, time.Duration, error) {
+	v, ok := def["latency"]
+	if !ok {
+		return 0, 0, nil
+	}
+
+	wrap := func(err error) error {
+		return fmt.Errorf("section 'latency': %w", err)
+	}
+
+	lDef, err := loadStringMap(v, "latency")
+	if err != nil {
+		return 0, 0, wrap(err)
+	}
+	if err := validateMapKeys(lDef, []string{"min", "max", "value", "jitter"}); err != nil {
+		return 0, 0, wrap(err)
+	}
+
+	var values [4]time.Duration
+	for i, key := range []string{"min", "max", "value", "jitter"} {
+		values[i], err = getOptionalDurationKey(lDef, key)
+		if err != nil {
+			return 0, 0, wrap(err)
+		}
+	}
+	minValue, maxValue, value, jitter := values[0], values[1], values[2], values[3]
+
+	if hasKey(lDef, "value") || hasKey(lDef, "jitter") {
+		if hasKey(lDef, "min") || hasKey(lDef, "max") {
+			return 0, 0, wrap(errors.New("keys 'min' and 'max' can't be used together with 'value' and 'jitter'"))
+		}
+		minValue, maxValue = value-jitter, value+jitter
+		if minValue < 0 {
+			minValue = 0
+		}
+		return minValue, maxValue, nil
+	}
+
+	if !hasKey(lDef, "max") {
+		maxValue = minValue
+	}
+	if maxValue < minValue {
+		return 0, 0, wrap(errors.New("value for the key 'max' can't be less than 'min'"))
+	}
+	return minValue, maxValue, nil
+}
+
+// apply decides whether the call with the given number must fail and marks the response
+// for the latency and the fault. The response is delayed and written with the fault by the writer,
+// when it is flushed without the lock of the mock service.
+func (f *faults) apply(w http.ResponseWriter, call int) {
+	if f.everyNth > 1 && call%f.everyNth != 0 {
+		return
+	}
+
+	f.mutex.Lock()
+	skip := f.rnd.Float64() >= f.probability
+	latency := f.latencyMin
+	if f.latencyMax > f.latencyMin {
+		latency += time.Duration(f.rnd.Int63n(int64(f.latencyMax - f.latencyMin + 1)))
+	}
+	f.mutex.Unlock()
+
+	if skip {
+		return
+	}
+	wrap, ok := w.(*wrapResponseWriter)
+	if !ok {
+		// the writer can't delay the response, so the delay is made here
+		time.Sleep(latency)
+		return
+	}
+	wrap.latency = latency
+	if f.kind == "" {
+		return
+	}
+	if f.kind == faultDropRequest {
+		wrap.drop = true
+	} else {
+		wrap.fault = f
+	}
+}
+
+// write sends the response collected by the writer with the fault.
+func (f *faults) write(w *wrapResponseWriter) error {
+	switch f.kind {
+	case faultTruncateBody:
+		// the declared Content-Length is kept, so the server closes the connection after the short body
+		body := w.body.Bytes()
+		size := f.bodyBytes
+		if size < 0 || size >= len(body) {
+			size = len(body) / 2
+		}
+		w.writeHeaders()
+		_, err := w.writer.Write(body[:size])
+		return err
+	case faultSlowBody:
+		w.writeHeaders()
+		flusher, _ := w.writer.(http.Flusher)
+		for _, b := range w.body.Bytes() {
+			if flusher != nil {
+				flusher.Flush()
+			}
+			time.Sleep(f.bytePause)
+			if _, err := w.writer.Write([]byte{b}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case faultResetAfterHeaders:
+		w.writeHeaders()
+		if flusher, ok := w.writer.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return closeConnection(w.writer, nil, true)
+	case faultMalformedChunked:
+		return closeConnection(w.writer, makeMalformedChunkedResponse(w), false)
+	default:
+		return fmt.Errorf("gonkex internal error: unknown fault kind '%s'", f.kind)
+	}
+}
+
+// makeMalformedChunkedResponse creates the response with the chunked body, where size of the chunk is not a number.
+func makeMalformedChunkedResponse(w *wrapResponseWriter) []byte {
+	keys := make([]string, 0, len(w.headers))
+	for key := range w.headers {
+		if key != "Content-Length" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\n", w.statusCode, http.StatusText(w.statusCode))
+	for _, key := range keys {
+		for _, value := range w.headers[key] {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	fmt.Fprintf(buf, "Transfer-Encoding: chunked\r\n\r\nzz\r\n%s\r\n", w.body.String())
+	return []byte(buf.String())
+}
+
+// closeConnection writes raw data to the connection and closes it. If reset is true, the client receives
+// RST instead of FIN.
+func closeConnection(w http.ResponseWriter, data []byte, reset bool) error {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return errors.New("gonkex internal error: inject fault: webserver does not support hijacking")
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		return fmt.Errorf("gonkex internal error: connection hijacking: %w", err)
+	}
+	if len(data) != 0 {
+		if _, err := conn.Write(data); err != nil {
+			_ = conn.Close()
+			return err
+		}
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok && reset {
+		_ = tcpConn.SetLinger(0)
+	}
+	return conn.Close()
+}
@@ -0,0 +1,197 @@
+package mocks_test
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startFaultMock(t *testing.T, definition string) (*mocks.Mocks, string) {
+	t.Helper()
+
+	m := mocks.NewNop("service")
+	require.NoError(t, m.Start())
+	t.Cleanup(m.Shutdown)
+
+	m.ResetRunningContext()
+	require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, definition))
+	return m, "http://" + m.Service("service").ServerAddr() + "/path"
+}
+
+// get returns status code, body and error of reading the response.
+func get(t *testing.T, url string) (int, string, error) {
+	t.Helper()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
+
+func Test_Faults_EveryNth(t *testing.T) {
+	m, url := startFaultMock(t, `
+service:
+  strategy: constant
+  body: "0123456789"
+  faults:
+    kind: dropRequest
+    everyNth: 3
+`)
+
+	for i := 1; i <= 6; i++ {
+		_, body, err := get(t, url)
+		if i%3 == 0 {
+			require.Error(t, err, "request #%d", i)
+		} else {
+			require.NoError(t, err, "request #%d", i)
+			require.Equal(t, "0123456789", body)
+		}
+	}
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_Faults_Probability(t *testing.T) {
+	m, url := startFaultMock(t, `
+service:
+  strategy: constant
+  body: "0123456789"
+  faults:
+    kind: dropRequest
+    probability: 0
+`)
+
+	for i := 0; i < 5; i++ {
+		_, _, err := get(t, url)
+		require.NoError(t, err)
+	}
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_Faults_Kinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		faults  string
+		check   func(t *testing.T, code int, body string, err error)
+		minTime time.Duration
+	}{
+		{
+			name: "truncateBody",
+			faults: `
+    kind: truncateBody
+    bodyBytes: 4`,
+			check: func(t *testing.T, code int, body string, err error) {
+				require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+				require.Equal(t, http.StatusAccepted, code)
+				require.Equal(t, "0123", body)
+			},
+		},
+		{
+			name: "slowBody",
+			faults: `
+    kind: slowBody
+    bytePause: 5ms`,
+			check: func(t *testing.T, code int, body string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "0123456789", body)
+			},
+			minTime: 50 * time.Millisecond,
+		},
+		{
+			name: "resetAfterHeaders",
+			faults: `
+    kind: resetAfterHeaders`,
+			check: func(t *testing.T, code int, body string, err error) {
+				require.Error(t, err)
+				require.Equal(t, http.StatusAccepted, code)
+				require.Empty(t, body)
+			},
+		},
+		{
+			name: "malformedChunked",
+			faults: `
+    kind: malformedChunked`,
+			check: func(t *testing.T, code int, body string, err error) {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "chunk")
+				require.Equal(t, http.StatusAccepted, code)
+			},
+		},
+		{
+			name: "latency",
+			faults: `
+    latency:
+      value: 60ms
+      jitter: 10ms`,
+			check: func(t *testing.T, code int, body string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "0123456789", body)
+			},
+			minTime: 50 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, url := startFaultMock(t, `
+service:
+  strategy: constant
+  statusCode: 202
+  body: "0123456789"
+  faults:`+tt.faults)
+
+			start := time.Now()
+			code, body, err := get(t, url)
+			tt.check(t, code, body, err)
+			require.GreaterOrEqual(t, time.Since(start), tt.minTime)
+			require.Empty(t, m.EndRunningContext(false))
+		})
+	}
+}
+
+func Test_Faults_Concurrent(t *testing.T) {
+	m, url := startFaultMock(t, `
+service:
+  strategy: constant
+  body: "0123456789"
+  faults:
+    kind: slowBody
+    bytePause: 20ms
+    latency:
+      min: 100ms
+`)
+
+	// each reply takes at least 300ms, concurrent replies must not wait for each other
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, body, err := get(t, url)
+			assert.NoError(t, err)
+			assert.Equal(t, "0123456789", body)
+		}()
+	}
+
+	// the journal is available while replies are written
+	require.Eventually(t, func() bool {
+		requests, err := m.Requests("service")
+		require.NoError(t, err)
+		return len(requests) == 5
+	}, 100*time.Millisecond, 5*time.Millisecond)
+
+	wg.Wait()
+	require.Less(t, time.Since(start), time.Second)
+	require.Empty(t, m.EndRunningContext(false))
+}
@@ -0,0 +1,72 @@
+package mocks
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestRecord describes the request received by the mock service.
+type RequestRecord struct {
+	Method  string
+	URL     string // path with query
+	Headers http.Header
+	Body    []byte
+	Time    time.Time
+}
+
+func newRequestRecord(r *http.Request, body []byte) RequestRecord {
+	return RequestRecord{
+		Method:  r.Method,
+		URL:     r.URL.RequestURI(),
+		Headers: r.Header.Clone(),
+		Body:    body,
+		Time:    time.Now(),
+	}
+}
+
+// ToMap converts the record to the object with fields method, url, path, query, headers, body and time.
+// Multiple values of the header are joined with comma. The body, which is valid JSON,
+// is stored as JSON, otherwise it's stored as a string.
+func (r *RequestRecord) ToMap() map[string]interface{} {
+	path, query, _ := strings.Cut(r.URL, "?")
+
+	headers := map[string]interface{}{}
+	for name, values := range r.Headers {
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		body = string(r.Body)
+	}
+
+	return map[string]interface{}{
+		"method":  r.Method,
+		"url":     r.URL,
+		"path":    path,
+		"query":   query,
+		"headers": headers,
+		"body":    body,
+		"time":    r.Time.Format(time.RFC3339Nano),
+	}
+}
+
+// Requests returns requests received by the mock service since the last reset of the running context.
+func (m *ServiceMock) Requests() []RequestRecord {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]RequestRecord(nil), m.requests...)
+}
+
+// Requests returns requests received by the mock service with the given name
+// since the last reset of the running context.
+func (m *Mocks) Requests(serviceName string) ([]RequestRecord, error) {
+	service := m.Service(serviceName)
+	if service == nil {
+		return nil, unknownMockError(serviceName)
+	}
+	return service.Requests(), nil
+}
@@ -0,0 +1,45 @@
+package mocks_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Requests(t *testing.T) {
+	m := mocks.NewNop("backend")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	url := "http://" + m.Service("backend").ServerAddr()
+	doRequest(t, http.MethodPost, url+"/orders?id=1", `{"name": "book"}`)
+	doRequest(t, http.MethodGet, url+"/orders/1", "")
+
+	requests, err := m.Requests("backend")
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+
+	item := requests[0].ToMap()
+	require.Equal(t, "POST", item["method"])
+	require.Equal(t, "/orders?id=1", item["url"])
+	require.Equal(t, "/orders", item["path"])
+	require.Equal(t, "id=1", item["query"])
+	require.Equal(t, map[string]interface{}{"name": "book"}, item["body"])
+	require.Contains(t, item["headers"], "User-Agent")
+
+	item = requests[1].ToMap()
+	require.Equal(t, "/orders/1", item["path"])
+	require.Equal(t, "", item["query"])
+	require.Equal(t, "", item["body"])
+
+	m.ResetRunningContext()
+	requests, err = m.Requests("backend")
+	require.NoError(t, err)
+	require.Empty(t, requests)
+
+	_, err = m.Requests("unknown")
+	require.Error(t, err)
+}
@@ -119,6 +119,7 @@ func (l *loaderImpl) loadDefinition(path string, rawDef interface{}) (*Definitio
 		"strategy",
 		"calls",
 		"order",
+		"faults",
 	}
 
 	replyStrategy, err := l.loadStrategy(path, strategyName, def, &ak)
@@ -133,12 +134,17 @@ func (l *loaderImpl) loadDefinition(path string, rawDef interface{}) (*Definitio
 	if err != nil {
 		return nil, wrap(err)
 	}
+	faults, err := loadFaults(def)
+	if err != nil {
+		return nil, wrap(err)
+	}
 	if err := validateMapKeys(def, ak); err != nil {
 		return nil, wrap(err)
 	}
 
 	res := NewDefinition(path, requestConstraints, replyStrategy, callsConstraint, orderValue)
 	res.order = l.order
+	res.faults = faults
 	return res, nil
 }
 
@@ -170,6 +176,12 @@ func (l *loaderImpl) loadStrategy(path, strategyName string, definition map[stri
 		return l.loadMethodVaryStrategy(path, definition)
 	case "dropRequest":
 		return l.loadDropRequestStrategy()
+	case "resource":
+		*ak = append(*ak, "basePath", "idField", "items", "expectedItems", "comparisonParams")
+		return l.loadResourceStrategy(path, definition)
+	case "proxy":
+		*ak = append(*ak, "upstream", "requestHeaders", "requestBodyReplace", "timeout", "record")
+		return l.loadProxyStrategy(definition)
 	default:
 		return nil, errors.New("unknown strategy")
 	}
@@ -16,11 +16,12 @@ var _ LoaderMocks = (*Mocks)(nil)
 // allowing them to be started, stopped, and configured as a group.
 type Mocks struct {
 	mocks map[string]*ServiceMock
+	admin adminServer
 }
 
 // New creates a new Mocks instance from a list of ServiceMock objects.
 func New(mocks ...*ServiceMock) *Mocks {
-	m := &Mocks{map[string]*ServiceMock{}}
+	m := &Mocks{mocks: map[string]*ServiceMock{}}
 	for _, v := range mocks {
 		m.SetMock(v)
 	}
@@ -65,9 +66,14 @@ func (m *Mocks) Shutdown() {
 	_ = m.ShutdownContext(ctx)
 }
 
-// ShutdownContext gracefully stops all mock servers using the provided context.
+// ShutdownContext gracefully stops all mock servers and the admin server using the provided context.
 func (m *Mocks) ShutdownContext(ctx context.Context) error {
 	errs := []string{}
+	if server := m.shutdownAdminServer(); server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Sprintf("admin: %s", err.Error()))
+		}
+	}
 	for _, v := range m.mocks {
 		if !v.IsStarted() {
 			continue
@@ -18,21 +18,41 @@ var _ LoaderMocks = (*ServiceMock)(nil)
 // allowing for controlled testing of code that interacts with external services.
 //
 // Each ServiceMock instance maintains its own HTTP server on a dynamically assigned port,
-// tracks errors that occur during request processing, and can be configured with
+// tracks errors that occur during request processing and received requests, and can be configured with
 // a set of checkers to validate incoming requests.
 type ServiceMock struct {
-	server            *http.Server
+	server            Server
+	serverFactory     ServerFactory
 	listener          net.Listener
 	mock              *Definition
 	defaultDefinition *Definition
 	mutex             sync.RWMutex
 	errors            []error
+	requests          []RequestRecord
 	checkers          []CheckerInterface
 	defaultPort       string
 
 	ServiceName string
 }
 
+// Server accepts connections of the mock service.
+// *http.Server implements this interface.
+type Server interface {
+	Serve(ln net.Listener) error
+	Shutdown(ctx context.Context) error
+}
+
+// ServerFactory creates server which passes requests to the handler of the mock service.
+// It allows serving the mock with protocols other than plain HTTP (e.g. gRPC).
+type ServerFactory func(addr string, handler http.Handler) Server
+
+func newHTTPServer(addr string, handler http.Handler) Server {
+	return &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+}
+
 // NewServiceMock creates a new ServiceMock instance with the given name and mock definition.
 // If the mock definition is nil, it creates a default definition with a fail reply.
 func NewServiceMock(serviceName string, mock *Definition) *ServiceMock {
@@ -51,10 +71,17 @@ func NewServiceMock(serviceName string, mock *Definition) *ServiceMock {
 		defaultDefinition: mock,
 		defaultPort:       port,
 		checkers:          []CheckerInterface{},
+		serverFactory:     newHTTPServer,
 		ServiceName:       serviceName,
 	}
 }
 
+// SetServerFactory replaces the factory used to create server of the mock service.
+// It must be called before the server is started.
+func (m *ServiceMock) SetServerFactory(factory ServerFactory) {
+	m.serverFactory = factory
+}
+
 // StartServer initializes and starts an HTTP server on localhost with a random port.
 // After starting the server, you can use ServerAddr() method to get the actual
 // address (including the randomly assigned port) where the server is listening.
@@ -73,10 +100,7 @@ func (m *ServiceMock) StartServerWithAddr(addr string) error {
 	wg.Add(1)
 
 	go func() {
-		server := &http.Server{
-			Addr:    addr,
-			Handler: m,
-		}
+		server := m.serverFactory(addr, m)
 
 		m.listener = ln
 		m.server = server
@@ -117,18 +141,36 @@ func (m *ServiceMock) ServerAddr() string {
 // Errors from both Definition execution and checkers are accumulated.
 // Implements the http.Handler interface.
 func (m *ServiceMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wrap := m.prepareResponse(w, r)
+	if wrap == nil {
+		return
+	}
+
+	// the response is written without the lock, so slow replies (e.g. latency and slowBody faults)
+	// don't block concurrent requests to the mock
+	if err := wrap.Flush(); err != nil {
+		m.mutex.Lock()
+		m.errors = append(m.errors, err)
+		m.mutex.Unlock()
+	}
+}
+
+// prepareResponse executes the mock definition and runs checkers. The response is collected
+// in the returned writer, nil is returned if there is nothing to send.
+func (m *ServiceMock) prepareResponse(w http.ResponseWriter, r *http.Request) *wrapResponseWriter {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	if m.mock == nil {
-		return
+		return nil
 	}
 
 	body, err := getRequestBodyCopy(r)
 	if err != nil {
 		m.errors = append(m.errors, err)
-		return
+		return nil
 	}
+	m.requests = append(m.requests, newRequestRecord(r, body))
 
 	wrap := createResponseWriterProxy(w)
 	m.errors = append(m.errors, m.mock.Execute(wrap, r)...)
@@ -140,10 +182,7 @@ func (m *ServiceMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		errs := c.CheckRequest(m.ServiceName, r, wrap.CreateHttpResponse()) // nolint:bodyclose // we have single copy of data
 		m.errors = append(m.errors, errs...)
 	}
-
-	if err := wrap.Flush(); err != nil {
-		m.errors = append(m.errors, err)
-	}
+	return wrap
 }
 
 // RoundTrip implements the http.RoundTripper interface, allowing ServiceMock to be used as a transport for HTTP clients.
@@ -185,15 +224,36 @@ func (m *ServiceMock) ResetDefinition() {
 	m.mock = m.defaultDefinition
 }
 
-// ResetRunningContext clears all accumulated errors and resets the mock definition's running context.
+// ResetRunningContext clears all accumulated errors and received requests and resets the mock definition's running context.
 func (m *ServiceMock) ResetRunningContext() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	m.errors = nil
+	m.requests = nil
 	m.mock.ResetRunningContext()
 }
 
+// ResetDefinitionContext clears all accumulated errors and resets the mock definition's running context,
+// but keeps the journal of received requests. It's used when the definition is replaced in the middle of the test.
+func (m *ServiceMock) ResetDefinitionContext() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.errors = nil
+	m.mock.ResetRunningContext()
+}
+
+// ResetJournal clears all accumulated errors and received requests, but keeps the running context
+// of the mock definition. It's used when the definition is shared by several tests.
+func (m *ServiceMock) ResetJournal() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.errors = nil
+	m.requests = nil
+}
+
 // EndRunningContext finalizes the running context and returns all accumulated errors.
 func (m *ServiceMock) EndRunningContext(intermediate bool) []error {
 	m.mutex.RLock()
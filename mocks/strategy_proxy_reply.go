@@ -0,0 +1,351 @@
+package mocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultProxyTimeout = 30 * time.Second
+
+// hop-by-hop headers, which must not be forwarded by proxy
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func (l *loaderImpl) loadProxyStrategy(def map[string]interface{}) (ReplyStrategy, error) {
+	upstream, err := getRequiredStringKey(def, "upstream", false)
+	if err != nil {
+		return nil, err
+	}
+	upstreamURL, err := url.Parse(upstream)
+	if err != nil || upstreamURL.Scheme == "" || upstreamURL.Host == "" {
+		return nil, fmt.Errorf("value for the key 'upstream' must be absolute URL")
+	}
+
+	headers, err := loadRequestHeaders(def)
+	if err != nil {
+		return nil, err
+	}
+	rewrites, err := loadBodyRewrites(def)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := getOptionalDurationKey(def, "timeout")
+	if err != nil {
+		return nil, err
+	}
+	recordFile, err := getOptionalStringKey(def, "record", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProxyReply(upstreamURL, headers, rewrites, timeout, recordFile), nil
+}
+
+func loadRequestHeaders(def map[string]interface{}) (map[string]string, error) {
+	h, ok := def["requestHeaders"]
+	if !ok {
+		return nil, nil
+	}
+
+	hMap, err := loadStringMap(h, "requestHeaders")
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{}
+	for key, v := range hMap {
+		value, ok := v.(string)
+		if !ok {
+			return nil, errors.New("'requestHeaders' requires string values")
+		}
+		headers[key] = value
+	}
+	return headers, nil
+}
+
+func loadBodyRewrites(def map[string]interface{}) ([]BodyRewrite, error) {
+	r, ok := def["requestBodyReplace"]
+	if !ok {
+		return nil, nil
+	}
+	items, ok := r.([]interface{})
+	if !ok {
+		return nil, errors.New("list under 'requestBodyReplace' key required")
+	}
+
+	var rewrites []BodyRewrite
+	for i, item := range items {
+		wrap := func(err error) error {
+			return fmt.Errorf("requestBodyReplace[%d]: %w", i, err)
+		}
+
+		itemDef, err := loadStringMap(item, "")
+		if err != nil {
+			return nil, wrap(err)
+		}
+		expr, err := getRequiredStringKey(itemDef, "regexp", false)
+		if err != nil {
+			return nil, wrap(err)
+		}
+		value, err := getRequiredStringKey(itemDef, "value", true)
+		if err != nil {
+			return nil, wrap(err)
+		}
+		if err := validateMapKeys(itemDef, []string{"regexp", "value"}); err != nil {
+			return nil, wrap(err)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, wrap(err)
+		}
+		rewrites = append(rewrites, BodyRewrite{Regexp: re, Value: value})
+	}
+	return rewrites, nil
+}
+
+// BodyRewrite replaces all matches of the regular expression in the body of the proxied request.
+// Value can contain references to submatches ($1, ${name}) as in regexp.Regexp.ReplaceAll.
+type BodyRewrite struct {
+	Regexp *regexp.Regexp
+	Value  string
+}
+
+// NewProxyReply creates strategy, which forwards requests to upstream and returns its replies.
+// If recordFile is not empty, the request/response pairs are saved to this file
+// as definition of the basedOnRequest strategy.
+func NewProxyReply(upstream *url.URL, headers map[string]string, rewrites []BodyRewrite,
+	timeout time.Duration, recordFile string) ReplyStrategy {
+	if timeout == 0 {
+		timeout = defaultProxyTimeout
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		// redirects of upstream are replied to the client as they are
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &proxyReply{
+		upstream:   upstream,
+		headers:    headers,
+		rewrites:   rewrites,
+		client:     client,
+		recordFile: recordFile,
+	}
+}
+
+type proxyReply struct {
+	upstream   *url.URL
+	headers    map[string]string
+	rewrites   []BodyRewrite
+	client     *http.Client
+	recordFile string
+
+	mutex    sync.Mutex
+	recorded *recordedDefinition
+}
+
+func (s *proxyReply) HandleRequest(w http.ResponseWriter, r *http.Request) []error {
+	body, err := getRequestBodyCopy(r)
+	if err != nil {
+		return []error{err}
+	}
+
+	resp, respBody, err := s.forward(r, body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return []error{fmt.Errorf("proxy request to upstream '%s': %w", s.upstream, err)}
+	}
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+
+	if s.recordFile != "" {
+		err = s.record(r, body, resp, respBody)
+		if err != nil {
+			return []error{fmt.Errorf("record reply to '%s': %w", s.recordFile, err)}
+		}
+	}
+	return nil
+}
+
+func (s *proxyReply) forward(r *http.Request, body []byte) (*http.Response, []byte, error) {
+	for _, rewrite := range s.rewrites {
+		body = rewrite.Regexp.ReplaceAll(body, []byte(rewrite.Value))
+	}
+
+	target := *s.upstream
+	target.Path = strings.TrimSuffix(target.Path, "/") + r.URL.Path
+	target.RawPath = ""
+	target.RawQuery = r.URL.RawQuery
+
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header = r.Header.Clone()
+	for _, h := range hopHeaders {
+		req.Header.Del(h)
+	}
+	// the transport requests compressed reply itself and decompresses it only if the header is not set,
+	// so the client and the recorded definition always get the plain body
+	req.Header.Del("Accept-Encoding")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, h := range hopHeaders {
+		resp.Header.Del(h)
+	}
+	resp.Header.Del("Content-Length")
+	return resp, respBody, nil
+}
+
+type recordedDefinition struct {
+	Strategy string             `yaml:"strategy"`
+	Uris     []*recordedVariant `yaml:"uris"`
+}
+
+type recordedVariant struct {
+	RequestConstraints []recordedConstraint `yaml:"requestConstraints"`
+	Strategy           string               `yaml:"strategy"`
+	StatusCode         int                  `yaml:"statusCode"`
+	Headers            map[string]string    `yaml:"headers,omitempty"`
+	Body               string               `yaml:"body"`
+}
+
+type recordedConstraint struct {
+	Kind   string `yaml:"kind"`
+	Method string `yaml:"method,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+	Query  string `yaml:"query,omitempty"`
+	Body   string `yaml:"body,omitempty"`
+}
+
+// record saves the reply to the file. The reply to the same request replaces the previous one,
+// so the file can be updated by repeated runs of the tests.
+func (s *proxyReply) record(r *http.Request, body []byte, resp *http.Response, respBody []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.recorded == nil {
+		recorded, err := readRecordedDefinition(s.recordFile)
+		if err != nil {
+			return err
+		}
+		s.recorded = recorded
+	}
+
+	variant := &recordedVariant{
+		RequestConstraints: makeRecordedConstraints(r, body),
+		Strategy:           "constant",
+		StatusCode:         resp.StatusCode,
+		Body:               string(respBody),
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		variant.Headers = map[string]string{"Content-Type": contentType}
+	}
+
+	replaced := false
+	for i, v := range s.recorded.Uris {
+		if sameConstraints(v.RequestConstraints, variant.RequestConstraints) {
+			s.recorded.Uris[i] = variant
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.recorded.Uris = append(s.recorded.Uris, variant)
+	}
+
+	content := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s.recorded); err != nil {
+		return err
+	}
+	return os.WriteFile(s.recordFile, content.Bytes(), 0o644)
+}
+
+func readRecordedDefinition(filename string) (*recordedDefinition, error) {
+	recorded := &recordedDefinition{Strategy: "basedOnRequest"}
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return recorded, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, recorded); err != nil {
+		return nil, err
+	}
+	if recorded.Strategy != "basedOnRequest" {
+		return nil, fmt.Errorf("file contains definition of strategy '%s' instead of 'basedOnRequest'", recorded.Strategy)
+	}
+	return recorded, nil
+}
+
+func makeRecordedConstraints(r *http.Request, body []byte) []recordedConstraint {
+	constraints := []recordedConstraint{
+		{Kind: "methodIs", Method: r.Method},
+		{Kind: "pathMatches", Path: r.URL.Path},
+	}
+	if r.URL.RawQuery != "" {
+		constraints = append(constraints, recordedConstraint{Kind: "queryMatches", Query: r.URL.RawQuery})
+	}
+	if len(body) != 0 {
+		kind := "bodyMatchesText"
+		if json.Valid(body) {
+			kind = "bodyMatchesJSON"
+		}
+		constraints = append(constraints, recordedConstraint{Kind: kind, Body: string(body)})
+	}
+	return constraints
+}
+
+func sameConstraints(a, b []recordedConstraint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
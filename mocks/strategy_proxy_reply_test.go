@@ -0,0 +1,188 @@
+package mocks_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/require"
+)
+
+func doRequest(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(respBody)
+}
+
+func Test_ProxyReply_Record(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"path": "`+r.URL.Path+`", "query": "`+r.URL.RawQuery+
+			`", "token": "`+r.Header.Get("X-Token")+`", "body": `+string(body)+`}`)
+	}))
+	defer upstream.Close()
+
+	m := mocks.NewNop("api")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+	addr := "http://" + m.Service("api").ServerAddr()
+
+	recordFile := filepath.Join(t.TempDir(), "api.yaml")
+	loadDefinition := func(content string) {
+		m.ResetRunningContext()
+		require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, content))
+	}
+
+	loadDefinition(`
+api:
+  strategy: proxy
+  upstream: ` + upstream.URL + `/v1
+  requestHeaders:
+    X-Token: secret
+  requestBodyReplace:
+    - regexp: '"id": (\d+)'
+      value: '"id": "$1"'
+  record: ` + recordFile + `
+`)
+
+	code, body := doRequest(t, http.MethodPost, addr+"/users?limit=1", `{"id": 1}`)
+	require.Equal(t, http.StatusCreated, code)
+	require.JSONEq(t, `{"path": "/v1/users", "query": "limit=1", "token": "secret", "body": {"id": "1"}}`, body)
+	code, _ = doRequest(t, http.MethodGet, addr+"/users", "")
+	require.Equal(t, http.StatusCreated, code)
+	require.Empty(t, m.EndRunningContext(false))
+
+	// recorded file is ready-to-use definition, which replies without upstream
+	upstream.Close()
+	recorded, err := os.ReadFile(recordFile)
+	require.NoError(t, err)
+	loadDefinition("api:\n" + indent(string(recorded)))
+
+	code, replayed := doRequest(t, http.MethodPost, addr+"/users?limit=1", `{"id": 1}`)
+	require.Equal(t, http.StatusCreated, code)
+	require.JSONEq(t, body, replayed)
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func Test_ProxyReply_RecordCompressed(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = io.WriteString(w, `{"status": "ok"}`)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = io.WriteString(gz, `{"status": "ok"}`)
+		_ = gz.Close()
+	}))
+	defer upstream.Close()
+
+	m := mocks.NewNop("api")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	recordFile := filepath.Join(t.TempDir(), "api.yaml")
+	require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, `
+api:
+  strategy: proxy
+  upstream: `+upstream.URL+`
+  record: `+recordFile+`
+`))
+
+	// default client sends "Accept-Encoding: gzip" and decompresses the reply
+	req, err := http.NewRequest(http.MethodGet, "http://"+m.Service("api").ServerAddr()+"/status", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Content-Encoding"))
+	require.Equal(t, `{"status": "ok"}`, string(body))
+	require.Empty(t, m.EndRunningContext(false))
+
+	recorded, err := os.ReadFile(recordFile)
+	require.NoError(t, err)
+	require.Contains(t, string(recorded), `body: '{"status": "ok"}'`)
+}
+
+func Test_ProxyReply_UpstreamError(t *testing.T) {
+	m := mocks.NewNop("api")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	m.ResetRunningContext()
+	require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, `
+api:
+  strategy: proxy
+  upstream: http://127.0.0.1:1
+`))
+
+	code, _ := doRequest(t, http.MethodGet, "http://"+m.Service("api").ServerAddr()+"/users", "")
+	require.Equal(t, http.StatusBadGateway, code)
+
+	errs := m.EndRunningContext(false)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "proxy request to upstream 'http://127.0.0.1:1'")
+}
+
+func Test_ProxyReply_Redirect(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.Redirect(w, r, "/home", http.StatusFound)
+			return
+		}
+		_, _ = io.WriteString(w, "home")
+	}))
+	defer upstream.Close()
+
+	m := mocks.NewNop("api")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	m.ResetRunningContext()
+	require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, `
+api:
+  strategy: proxy
+  upstream: `+upstream.URL+`
+`))
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get("http://" + m.Service("api").ServerAddr() + "/login")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// redirect of upstream is replied as is
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	require.Equal(t, "/home", resp.Header.Get("Location"))
+	require.Empty(t, m.EndRunningContext(false))
+}
+
+func indent(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	return "  " + strings.Join(lines, "\n  ") + "\n"
+}
@@ -0,0 +1,404 @@
+package mocks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/compare"
+)
+
+const defaultIDField = "id"
+
+func (l *loaderImpl) loadResourceStrategy(path string, def map[string]interface{}) (ReplyStrategy, error) {
+	basePath, err := getOptionalStringKey(def, "basePath", true)
+	if err != nil {
+		return nil, err
+	}
+	idField, err := getOptionalStringKey(def, "idField", false)
+	if err != nil {
+		return nil, err
+	}
+	if idField == "" {
+		idField = defaultIDField
+	}
+
+	items, err := loadResourceItems(def, "items")
+	if err != nil {
+		return nil, err
+	}
+	var expectedItems []interface{}
+	if hasKey(def, "expectedItems") {
+		expectedItems, err = loadResourceItems(def, "expectedItems")
+		if err != nil {
+			return nil, err
+		}
+	}
+	params, err := readCompareParams(def)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResourceReply(path, basePath, idField, items, expectedItems, params)
+}
+
+// loadResourceItems converts list of YAML objects to the list of JSON objects.
+func loadResourceItems(def map[string]interface{}, key string) ([]interface{}, error) {
+	value, ok := def[key]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("list under '%s' key required", key)
+	}
+
+	items := []interface{}{}
+	for i, v := range list {
+		item, err := toJSONValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", key, i, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// toJSONValue converts the value to the form produced by json.Unmarshal.
+func toJSONValue(value interface{}) (interface{}, error) {
+	if m, err := loadStringMap(value, ""); err == nil {
+		res := map[string]interface{}{}
+		for k, v := range m {
+			if res[k], err = toJSONValue(v); err != nil {
+				return nil, err
+			}
+		}
+		value = res
+	} else if list, ok := value.([]interface{}); ok {
+		res := make([]interface{}, len(list))
+		for i, v := range list {
+			if res[i], err = toJSONValue(v); err != nil {
+				return nil, err
+			}
+		}
+		value = res
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+// NewResourceReply creates strategy, which stores JSON objects in memory and serves
+// REST requests to them: list (GET basePath), create (POST basePath), get, replace, update
+// and delete (GET, PUT, PATCH and DELETE basePath/<id>).
+//
+// The store is filled with items on every reset of the running context. If expectedItems is not nil,
+// the content of the store is compared with it at the end of the running context.
+func NewResourceReply(path, basePath, idField string, items, expectedItems []interface{},
+	params compare.Params) (ReplyStrategy, error) {
+	s := &resourceReply{
+		path:          path,
+		basePath:      "/" + strings.Trim(basePath, "/"),
+		idField:       idField,
+		expectedItems: expectedItems,
+		compareParams: params,
+	}
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("items[%d]: must be an object", i)
+		}
+		id, err := s.getID(obj)
+		if err != nil {
+			return nil, fmt.Errorf("items[%d]: %w", i, err)
+		}
+		for _, seed := range s.seed {
+			if seed.id == id {
+				return nil, fmt.Errorf("items[%d]: duplicate item with id '%s'", i, id)
+			}
+		}
+		s.seed = append(s.seed, &resourceItem{id, obj})
+	}
+	s.ResetRunningContext()
+	return s, nil
+}
+
+var _ contextAwareStrategy = (*resourceReply)(nil)
+
+type resourceItem struct {
+	id    string
+	value map[string]interface{}
+}
+
+type resourceReply struct {
+	path          string
+	basePath      string
+	idField       string
+	seed          []*resourceItem
+	expectedItems []interface{}
+	compareParams compare.Params
+
+	mutex sync.Mutex
+	items []*resourceItem
+}
+
+func (s *resourceReply) HandleRequest(w http.ResponseWriter, r *http.Request) []error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id, isItem, ok := s.parsePath(r.URL.Path)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return unhandledRequestError(r)
+	}
+
+	var err error
+	switch {
+	case !isItem && r.Method == http.MethodGet:
+		list := []interface{}{}
+		for _, item := range s.items {
+			list = append(list, item.value)
+		}
+		writeJSON(w, http.StatusOK, list)
+	case !isItem && r.Method == http.MethodPost:
+		err = s.create(w, r)
+	case isItem && r.Method == http.MethodGet:
+		if item := s.find(id); item != nil {
+			writeJSON(w, http.StatusOK, item.value)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case isItem && r.Method == http.MethodPut:
+		err = s.replace(w, r, id)
+	case isItem && r.Method == http.MethodPatch:
+		err = s.update(w, r, id)
+	case isItem && r.Method == http.MethodDelete:
+		if s.remove(id) {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		err = fmt.Errorf("method %s is not supported for path '%s'", r.Method, r.URL.Path)
+	}
+
+	if err != nil {
+		return []error{colorize.NewEntityError("strategy %s", "resource").WithSubError(err).
+			WithPostfix(makeRequestWasParts(r))}
+	}
+	return nil
+}
+
+// parsePath returns id of the item if the path points to the item of collection.
+func (s *resourceReply) parsePath(path string) (string, bool, bool) {
+	path = "/" + strings.Trim(path, "/")
+	if path == s.basePath {
+		return "", false, true
+	}
+	prefix := strings.TrimSuffix(s.basePath, "/") + "/"
+	if !strings.HasPrefix(path, prefix) {
+		return "", false, false
+	}
+	id := strings.TrimPrefix(path, prefix)
+	if strings.Contains(id, "/") {
+		return "", false, false
+	}
+	return id, true, true
+}
+
+func (s *resourceReply) create(w http.ResponseWriter, r *http.Request) error {
+	obj, err := readJSONObject(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return err
+	}
+	if _, ok := obj[s.idField]; !ok {
+		obj[s.idField] = s.nextID()
+	}
+	id, err := s.getID(obj)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return err
+	}
+	if s.find(id) != nil {
+		w.WriteHeader(http.StatusConflict)
+		return nil
+	}
+
+	s.items = append(s.items, &resourceItem{id, obj})
+	writeJSON(w, http.StatusCreated, obj)
+	return nil
+}
+
+func (s *resourceReply) replace(w http.ResponseWriter, r *http.Request, id string) error {
+	obj, err := readJSONObject(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return err
+	}
+	s.setID(obj, id)
+
+	if item := s.find(id); item != nil {
+		item.value = obj
+		writeJSON(w, http.StatusOK, obj)
+		return nil
+	}
+	s.items = append(s.items, &resourceItem{id, obj})
+	writeJSON(w, http.StatusCreated, obj)
+	return nil
+}
+
+func (s *resourceReply) update(w http.ResponseWriter, r *http.Request, id string) error {
+	obj, err := readJSONObject(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return err
+	}
+
+	item := s.find(id)
+	if item == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	item.value = mergePatch(item.value, obj)
+	s.setID(item.value, id)
+	writeJSON(w, http.StatusOK, item.value)
+	return nil
+}
+
+func (s *resourceReply) remove(id string) bool {
+	for i, item := range s.items {
+		if item.id == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *resourceReply) find(id string) *resourceItem {
+	for _, item := range s.items {
+		if item.id == id {
+			return item
+		}
+	}
+	return nil
+}
+
+func (s *resourceReply) getID(obj map[string]interface{}) (string, error) {
+	switch v := obj[s.idField].(type) {
+	case string:
+		if v != "" {
+			return v, nil
+		}
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("field '%s' must be non-empty string or number", s.idField)
+}
+
+// setID sets id of the item from the path, the numeric ids are kept as numbers.
+func (s *resourceReply) setID(obj map[string]interface{}, id string) {
+	if current, err := s.getID(obj); err == nil && current == id {
+		return
+	}
+	if v, err := strconv.ParseFloat(id, 64); err == nil && strconv.FormatFloat(v, 'f', -1, 64) == id {
+		obj[s.idField] = v
+		return
+	}
+	obj[s.idField] = id
+}
+
+// nextID returns the number greater than all numeric ids in the store.
+func (s *resourceReply) nextID() float64 {
+	var maxID float64
+	for _, item := range s.items {
+		if v, err := strconv.ParseFloat(item.id, 64); err == nil && v > maxID {
+			maxID = v
+		}
+	}
+	return maxID + 1
+}
+
+func (s *resourceReply) ResetRunningContext() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.items = nil
+	for _, item := range s.seed {
+		value, _ := toJSONValue(item.value) // deep copy of valid JSON object can't fail
+		s.items = append(s.items, &resourceItem{item.id, value.(map[string]interface{})})
+	}
+}
+
+func (s *resourceReply) EndRunningContext(intermediate bool) []error {
+	if intermediate || s.expectedItems == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	actual := []interface{}{}
+	for _, item := range s.items {
+		actual = append(actual, item.value)
+	}
+
+	var errs []error
+	for _, err := range compare.Compare(s.expectedItems, actual, s.compareParams) {
+		errs = append(errs, colorize.NewPathError(s.path+".expectedItems",
+			colorize.NewEntityError("content of %s does not match", "resource").WithSubError(err)))
+	}
+	return errs
+}
+
+func readJSONObject(r *http.Request) (map[string]interface{}, error) {
+	body, err := getRequestBodyCopy(r)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil || obj == nil {
+		return nil, errors.New("request body must be JSON object")
+	}
+	return obj, nil
+}
+
+// mergePatch applies JSON merge patch (RFC 7396) to the object.
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		patchObj, ok := value.(map[string]interface{})
+		if !ok {
+			target[key] = value
+			continue
+		}
+		targetObj, ok := target[key].(map[string]interface{})
+		if !ok {
+			targetObj = map[string]interface{}{}
+		}
+		target[key] = mergePatch(targetObj, patchObj)
+	}
+	return target
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	data, _ := json.Marshal(value)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(data)
+}
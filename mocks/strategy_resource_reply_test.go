@@ -0,0 +1,104 @@
+package mocks_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResourceReply(t *testing.T) {
+	m := mocks.NewNop("users")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+	addr := "http://" + m.Service("users").ServerAddr()
+
+	m.ResetRunningContext()
+	require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, `
+users:
+  strategy: resource
+  basePath: /api/users
+  items:
+    - id: 1
+      name: John
+      address:
+        city: Paris
+    - id: 2
+      name: Mary
+  expectedItems:
+    - id: 1
+      name: John
+      address:
+        city: London
+        zip: "123"
+    - id: 3
+      name: Bob
+`))
+
+	tests := []struct {
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{http.MethodGet, "/api/users", "", 200, `[{"id": 1, "name": "John", "address": {"city": "Paris"}}, {"id": 2, "name": "Mary"}]`},
+		{http.MethodGet, "/api/users/2", "", 200, `{"id": 2, "name": "Mary"}`},
+		{http.MethodGet, "/api/users/5", "", 404, ``},
+		{http.MethodPost, "/api/users", `{"name": "Bob"}`, 201, `{"id": 3, "name": "Bob"}`},
+		{http.MethodPost, "/api/users", `{"id": 3, "name": "Bob"}`, 409, ``},
+		{http.MethodPatch, "/api/users/1", `{"address": {"city": "London", "zip": "123"}}`, 200,
+			`{"id": 1, "name": "John", "address": {"city": "London", "zip": "123"}}`},
+		{http.MethodPut, "/api/users/2", `{"name": "Mary Smith"}`, 200, `{"id": 2, "name": "Mary Smith"}`},
+		{http.MethodDelete, "/api/users/2", "", 204, ``},
+		{http.MethodDelete, "/api/users/2", "", 404, ``},
+	}
+	for _, tt := range tests {
+		code, body := doRequest(t, tt.method, addr+tt.path, tt.body)
+		require.Equal(t, tt.wantCode, code, "%s %s", tt.method, tt.path)
+		if tt.wantBody != "" {
+			require.JSONEq(t, tt.wantBody, body, "%s %s", tt.method, tt.path)
+		}
+	}
+	require.Empty(t, m.EndRunningContext(false))
+
+	// the store is restored from items
+	m.ResetRunningContext()
+	_, body := doRequest(t, http.MethodGet, addr+"/api/users/1", "")
+	require.JSONEq(t, `{"id": 1, "name": "John", "address": {"city": "Paris"}}`, body)
+
+	errs := m.EndRunningContext(false)
+	require.Len(t, errs, 4)
+	for _, err := range errs {
+		require.Contains(t, err.Error(), "mock 'users': path '$.expectedItems': content of 'resource' does not match: path '$[")
+	}
+}
+
+func Test_ResourceReply_Errors(t *testing.T) {
+	m := mocks.NewNop("users")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+	addr := "http://" + m.Service("users").ServerAddr()
+
+	m.ResetRunningContext()
+	require.NoError(t, mocks.NewYamlLoader(nil).LoadStringDefinition(m, `
+users:
+  strategy: resource
+  basePath: /users
+`))
+
+	code, _ := doRequest(t, http.MethodPost, addr+"/users", `not json`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = doRequest(t, http.MethodPost, addr+"/users/1", `{}`)
+	require.Equal(t, http.StatusMethodNotAllowed, code)
+	code, _ = doRequest(t, http.MethodGet, addr+"/orders", "")
+	require.Equal(t, http.StatusNotFound, code)
+
+	errs := m.EndRunningContext(false)
+	require.Len(t, errs, 3)
+	require.Contains(t, errs[0].Error(), "strategy 'resource': request body must be JSON object")
+	require.Contains(t, errs[1].Error(), "strategy 'resource': method POST is not supported for path '/users/1'")
+	require.Contains(t, errs[2].Error(), "unhandled request to mock")
+}
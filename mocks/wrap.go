@@ -24,6 +24,8 @@ func createResponseWriterProxy(rw http.ResponseWriter) *wrapResponseWriter {
 
 type wrapResponseWriter struct {
 	drop       bool
+	fault      *faults
+	latency    time.Duration
 	statusCode int
 	headers    http.Header
 	body       *bytes.Buffer
@@ -43,17 +45,27 @@ func (w *wrapResponseWriter) Write(b []byte) (int, error) {
 }
 
 func (w *wrapResponseWriter) Flush() error {
+	if w.latency > 0 {
+		time.Sleep(w.latency)
+	}
 	if w.drop {
 		return dropConnection(w.writer)
 	}
+	if w.fault != nil {
+		return w.fault.write(w)
+	}
+	w.writeHeaders()
+	_, err := w.writer.Write(w.body.Bytes())
+	return err
+}
+
+func (w *wrapResponseWriter) writeHeaders() {
 	for key, values := range w.headers {
 		for _, value := range values {
 			w.writer.Header().Add(key, value)
 		}
 	}
 	w.writer.WriteHeader(w.statusCode)
-	_, err := w.writer.Write(w.body.Bytes())
-	return err
 }
 
 func (w *wrapResponseWriter) fixResponse() {
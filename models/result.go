@@ -1,5 +1,9 @@
 package models
 
+import (
+	"time"
+)
+
 // DatabaseResult represents the result of a database check
 // Contains both the query that was executed and the response records
 type DatabaseResult struct {
@@ -7,6 +11,35 @@ type DatabaseResult struct {
 	Response []string // The records returned from the database as JSON items serialized to strings
 }
 
+// MessagesResult represents messages captured by the message check
+type MessagesResult struct {
+	Topic    string   // Name of the topic
+	Messages []string // The captured messages serialized to JSON strings
+}
+
+// WebSocketMessage is the result of the receive step of WebSocket test
+type WebSocketMessage struct {
+	Step    int    // Index of the step
+	Message string // The received message
+	Error   error  // Error of receiving (e.g. timeout), the message is empty in this case
+}
+
+// StreamEvent is the event (or NDJSON line) read from the streaming response
+type StreamEvent struct {
+	Event string // Type of SSE event (empty for NDJSON)
+	Data  string // Data of SSE event or NDJSON line
+}
+
+// Timings contains durations of the HTTP request phases
+// Phases which were not performed (e.g. DNS lookup for reused connection) have zero duration
+type Timings struct {
+	DNSLookup    time.Duration // Time spent on resolving the host name
+	Connect      time.Duration // Time spent on establishing the TCP connection
+	TLSHandshake time.Duration // Time spent on the TLS handshake
+	FirstByte    time.Duration // Time from the start of the request to the first byte of the response (TTFB)
+	Total        time.Duration // Total duration of the request including reading of the response body
+}
+
 // Result contains the complete results of a test execution
 // Includes both HTTP request/response details and database check results
 type Result struct {
@@ -19,11 +52,18 @@ type Result struct {
 	ResponseContentType string              // The content type of the response
 	ResponseHeaders     map[string][]string // All HTTP response headers
 	ResponseBody        string              // The body of the HTTP response
+	Timings             Timings             // Durations of the HTTP request phases
 
-	Errors         []error          // Any errors encountered during test execution
-	Test           TestInterface    // Reference to the test case that was executed
-	DatabaseResult []DatabaseResult // Results of database checks after the request
-	ShowHeaders    bool             // The checker can force display of request headers with this flag
+	Errors         []error            // Any errors encountered during test execution
+	Test           TestInterface      // Reference to the test case that was executed
+	DatabaseResult []DatabaseResult   // Results of database checks after the request
+	Messages       []MessagesResult   // Messages captured by message checks after the request
+	MockRequests   map[string]string  // Requests received by mocks, serialized to JSON array by service name
+	WebSocket      []WebSocketMessage // Messages received on receive steps of WebSocket test
+	Stream         []StreamEvent      // Events read from the streaming response
+	StreamError    error              // Error of reading the streaming response (e.g. timeout)
+	Steps          []*Result          // Results of executed steps of the multi-step test
+	ShowHeaders    bool               // The checker can force display of request headers with this flag
 }
 
 // Passed returns true if the test execution passed without errors
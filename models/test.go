@@ -28,6 +28,52 @@ type DatabaseCheck interface {
 	DbQueryString() string                 // Storage query to execute against the database
 	DbResponseJson() []string              // Expected records as serialized JSON strings
 	GetComparisonParams() ComparisonParams // Comparison parameters for database response
+	GetEventually() Eventually             // Parameters of repeating the query until the response matches
+}
+
+// Eventually defines how a check should be repeated until it succeeds
+type Eventually interface {
+	Timeout() time.Duration  // Maximum time of repeating the check (zero means the check is performed once)
+	Interval() time.Duration // Delay between attempts of the check
+}
+
+// BusMessage describes a message of the message bus topic
+type BusMessage interface {
+	Key() string                // Key of the message (empty if not set)
+	Value() string              // Value of the message
+	Headers() map[string]string // Headers of the message
+}
+
+// TopicMessages represents messages published to the topic of the message bus before the request
+type TopicMessages interface {
+	Topic() string          // Name of the topic
+	Messages() []BusMessage // Messages to publish
+}
+
+// MessageCheck represents messages expected to be produced to the topic of the message bus
+type MessageCheck interface {
+	Topic() string                         // Name of the topic
+	Messages() []BusMessage                // Expected messages
+	Timeout() time.Duration                // Maximum time of waiting for the messages (zero if not set)
+	GetComparisonParams() ComparisonParams // Comparison parameters for the messages
+}
+
+// MockRequest describes the request expected to be received by the mock service.
+// Empty fields are not checked.
+type MockRequest interface {
+	Method() string             // HTTP method of the request
+	Path() string               // Path of the request
+	Query() string              // Query string of the request
+	Headers() map[string]string // Headers of the request
+	Body() string               // Body of the request
+}
+
+// MockRequestsCheck represents expectations for the requests received by the mock service during the test
+type MockRequestsCheck interface {
+	ServiceName() string                   // Name of the mock service
+	Count() (int, bool)                    // Expected number of requests (false if not set)
+	Requests() []MockRequest               // Expected requests in the order of receiving (nil if not set)
+	GetComparisonParams() ComparisonParams // Comparison parameters for the requests
 }
 
 // RetryPolicy defines how tests should be retried if they fail
@@ -37,6 +83,57 @@ type RetryPolicy interface {
 	SuccessCount() int    // Required number of consecutive successful test passes to mark as successful
 }
 
+// ResponseTime defines expectations for the duration of the HTTP request
+type ResponseTime interface {
+	MaxDuration() time.Duration // Maximum allowed duration of the request (zero means no limit)
+}
+
+// ClientParams contains settings of the HTTP client which override the runner settings for the test
+// Empty values mean that the runner settings are used
+type ClientParams interface {
+	CAFile() string     // Path to PEM file with CA certificates used to verify the server certificate
+	CertFile() string   // Path to PEM file with client certificate
+	KeyFile() string    // Path to PEM file with private key of client certificate
+	ServerName() string // Name used to verify the server certificate
+	Protocol() string   // HTTP protocol: http1, http2 or h2c
+}
+
+// GRPCParams describes the gRPC call made by the test
+type GRPCParams interface {
+	FullMethod() string          // Full name of the called method, e.g. "package.Service/Method"
+	Metadata() map[string]string // Metadata sent with the request
+	Timeout() time.Duration      // Deadline of the call (0 means default)
+}
+
+// WebSocketParams describes the conversation of WebSocket test
+type WebSocketParams interface {
+	Steps() []WebSocketStep // Actions performed after the connection is established
+}
+
+// WebSocketStep describes one action of WebSocket conversation:
+// either sending of the message or waiting for the message from the server
+type WebSocketStep interface {
+	Send() string                          // Message sent to the server (empty for receive step)
+	Receive() string                       // Expected message from the server (empty for send step)
+	Timeout() time.Duration                // Maximum time of waiting for the message
+	GetComparisonParams() ComparisonParams // Parameters for comparing the received message
+}
+
+// StreamParams describes expected events of the streaming response (Server-Sent Events or NDJSON)
+type StreamParams interface {
+	Format() string          // Format of the stream: "sse", "ndjson" or empty (detected by Content-Type)
+	Timeout() time.Duration  // Maximum time of waiting for all expected events
+	IgnoreOrdering() bool    // Events can be received in any order
+	Events() []ExpectedEvent // Expected events
+}
+
+// ExpectedEvent describes one expected event of the streaming response
+type ExpectedEvent interface {
+	Event() string                         // Type of SSE event (empty means any type)
+	Data() string                          // Data of SSE event or NDJSON line
+	GetComparisonParams() ComparisonParams // Parameters for comparing the data of the event
+}
+
 // Form represents multipart/form-data for file uploads and form submissions
 type Form interface {
 	GetFiles() map[string]string  // Map of field name to file path for file uploads
@@ -48,6 +145,17 @@ type Script interface {
 	Timeout() time.Duration // Timeout for execution (3 seconds by default)
 }
 
+// FileHook defines actions performed once per test file
+// (before the first test in the file or after the last one)
+type FileHook interface {
+	Fixtures() []string              // List of fixtures to load
+	Script() Script                  // Script to execute
+	GetVariables() map[string]string // Variables available for all tests in the file
+	// ServiceMocks returns mocks loaded once for all tests in the file,
+	// with strings of the definitions processed by the provided function
+	ServiceMocks(perform func(string) string) map[string]interface{}
+}
+
 type MocksParams interface {
 	SkipMocksResetBeforeTest() bool
 	SkipMocksResetAfterTest() bool
@@ -69,17 +177,28 @@ type TestInterface interface {
 	GetForm() Form              // Form data for multipart/form-data requests
 
 	GetMeta(key string) interface{} // Additional metadata for the test
+	GetTags() []string              // Tags used to select tests for execution
+	GetDependencies() []string      // Names of tests from the same file which must pass before this test
 
 	GetStatus() Status                                     // Test execution status (focus, broken, skipped)
 	GetResponses() map[int]string                          // Expected responses for different HTTP status codes
 	GetResponse(code int) (string, bool)                   // Get expected response for a specific HTTP status code
 	GetResponseHeaders(code int) (map[string]string, bool) // Get expected response headers for a specific status code
 
-	Fixtures() []string                 // List of fixtures to load before test execution
-	GetDatabaseChecks() []DatabaseCheck // Database checks to perform after the request
+	Fixtures() []string                   // List of fixtures to load before test execution
+	GetDatabaseChecks() []DatabaseCheck   // Database checks to perform after the request
+	PublishMessages() []TopicMessages     // Messages published to the message bus before the request
+	GetMessageChecks() []MessageCheck     // Messages expected to be produced to the message bus
+	GetMockRequests() []MockRequestsCheck // Requests expected to be received by mocks
 
 	GetComparisonParams() ComparisonParams // Comparison parameters for response checking
 	GetRetryPolicy() RetryPolicy           // Retry policy for failed tests
+	GetResponseTime() ResponseTime         // Expectations for the duration of the request
+	GetClientParams() ClientParams         // Settings of the HTTP client for the test
+	GetGRPC() GRPCParams                   // gRPC call of the test (nil for HTTP tests)
+	GetWebSocket() WebSocketParams         // WebSocket conversation of the test (nil for HTTP tests)
+	GetStream() StreamParams               // Expected events of the streaming response (nil if not set)
+	GetSteps() []TestInterface             // Requests of the multi-step test (empty for single request test)
 
 	ServiceMocks() map[string]interface{} // Mocks for external services
 	ServiceMocksParams() MocksParams
@@ -98,6 +217,8 @@ type TestInterface interface {
 	GetLineNumber() int    // The line number in the file where the test declaration begins
 	FirstTestInFile() bool // Whether this is the first test in the file
 	LastTestInFile() bool  // Whether this is the last test in the file
+	BeforeAll() FileHook   // Actions performed before the first test in the file (nil if not defined)
+	AfterAll() FileHook    // Actions performed after the last test in the file (nil if not defined)
 	OneOfCase() bool       // Whether this test is part of a case-based test
 
 	SetStatus(status Status) // Set the execution status of the test
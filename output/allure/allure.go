@@ -103,14 +103,19 @@ func (a *Allure) EndStep(status string, end time.Time) {
 }
 
 func (a *Allure) AddAttachment(attachmentName, content string, typ string) {
+	currentState[a.GetCurrentSuite()].AddAttachment(a.NewAttachment(attachmentName, content))
+}
+
+// NewAttachment writes the content to the file and returns the attachment, which refers to it.
+func (a *Allure) NewAttachment(attachmentName, content string) *beans.Attachment {
 	mime := "text/plain"
 	ext := "txt"
 	name, _ := writeAttachment(a.TargetDir, content, ext)
-	currentState[a.GetCurrentSuite()].AddAttachment(beans.NewAttachment(
+	return beans.NewAttachment(
 		attachmentName,
 		mime,
 		name,
-		len(content)))
+		len(content))
 }
 
 func (a *Allure) PendingCase(testName string, start time.Time) {
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/output/allure/beans"
 )
 
 type Output struct {
@@ -48,6 +49,11 @@ func (o *Output) Process(t models.TestInterface, result *models.Result) error {
 
 	o.allure.AddAttachment("Request", fmt.Sprintf("Query: %s\n Body: %s", result.Query, result.RequestBody), "txt")
 	o.allure.AddAttachment("Response", fmt.Sprintf("Body: %s", result.ResponseBody), "txt")
+	if result.Timings.Total != 0 {
+		o.allure.AddAttachment("Timings", fmt.Sprintf("Total: %s\nDNS lookup: %s\nConnect: %s\nTLS handshake: %s\nTTFB: %s",
+			result.Timings.Total, result.Timings.DNSLookup, result.Timings.Connect,
+			result.Timings.TLSHandshake, result.Timings.FirstByte), "txt")
+	}
 
 	for i, dbresult := range result.DatabaseResult {
 		if dbresult.Query != "" {
@@ -56,11 +62,35 @@ func (o *Output) Process(t models.TestInterface, result *models.Result) error {
 		}
 	}
 
+	for i, messages := range result.Messages {
+		o.allure.AddAttachment(fmt.Sprintf("Messages #%d", i+1),
+			fmt.Sprintf("Topic: %s\nMessages: %s", messages.Topic, messages.Messages), "txt")
+	}
+
+	for _, step := range result.Steps {
+		testCase.AddStep(o.makeStep(step))
+	}
+
 	status, err := getAllureStatus(result)
 	o.allure.EndCase(status, err, timeNow())
 	return nil
 }
 
+// makeStep describes the executed step of the multi-step test.
+func (o *Output) makeStep(result *models.Result) *beans.Step {
+	step := beans.NewStep(result.Test.GetName(), timeNow())
+	step.AddAttachment(o.allure.NewAttachment("Request",
+		fmt.Sprintf("Query: %s\n Body: %s", result.Query, result.RequestBody)))
+	step.AddAttachment(o.allure.NewAttachment("Response", fmt.Sprintf("Body: %s", result.ResponseBody)))
+
+	status := "passed"
+	if len(result.Errors) != 0 {
+		status = "failed"
+	}
+	step.End(status, timeNow())
+	return step
+}
+
 func (o *Output) Finalize() error {
 	return o.allure.EndSuite(timeNow())
 }
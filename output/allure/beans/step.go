@@ -7,12 +7,16 @@ import (
 type Step struct {
 	Parent *Step `xml:"-"`
 
-	Status      string        `xml:"status,attr"`
-	Start       int64         `xml:"start,attr"`
-	Stop        int64         `xml:"stop,attr"`
-	Name        string        `xml:"name"`
-	Steps       []*Step       `xml:"steps"`
-	Attachments []*Attachment `xml:"attachments"`
+	Status string `xml:"status,attr"`
+	Start  int64  `xml:"start,attr"`
+	Stop   int64  `xml:"stop,attr"`
+	Name   string `xml:"name"`
+	Steps  struct {
+		Steps []*Step `xml:"step"`
+	} `xml:"steps"`
+	Attachments struct {
+		Attachment []*Attachment `xml:"attachment"`
+	} `xml:"attachments"`
 }
 
 func NewStep(name string, start time.Time) *Step {
@@ -29,10 +33,14 @@ func (s *Step) End(status string, end time.Time) {
 
 func (s *Step) AddStep(step *Step) {
 	if step != nil {
-		s.Steps = append(s.Steps, step)
+		s.Steps.Steps = append(s.Steps.Steps, step)
 	}
 }
 
+func (s *Step) AddAttachment(attach *Attachment) {
+	s.Attachments.Attachment = append(s.Attachments.Attachment, attach)
+}
+
 func microSeconds(t time.Time) int64 {
 	return t.UnixNano() / 1000
 }
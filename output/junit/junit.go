@@ -0,0 +1,262 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/output"
+)
+
+var _ output.ExtendedOutputInterface = (*Output)(nil)
+
+// redefined in tests
+var (
+	writeFile = os.WriteFile
+	mkDirAll  = os.MkdirAll
+	timeNow   = time.Now
+)
+
+type testSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []*testSuite `xml:"testsuite"`
+}
+
+type testSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []*testCase `xml:"testcase"`
+
+	start    time.Time
+	duration time.Duration
+}
+
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	File      string   `xml:"file,attr,omitempty"`
+	Line      int      `xml:"line,attr,omitempty"`
+	Time      string   `xml:"time,attr"`
+	Failure   *failure `xml:"failure,omitempty"`
+	Error     *failure `xml:"error,omitempty"`
+	Skipped   *skipped `xml:"skipped,omitempty"`
+	SystemOut string   `xml:"system-out,omitempty"`
+}
+
+type failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Output collects test results and writes them as JUnit XML report.
+// It creates one testsuite for every test file and one testcase for every test (or test case).
+type Output struct {
+	mutex    sync.Mutex
+	fileName string
+	report   testSuites
+	suites   map[string]*testSuite
+	started  map[string][]startedTest
+}
+
+// startedTest is the test, whose result is not processed yet.
+type startedTest struct {
+	test  models.TestInterface
+	start time.Time
+}
+
+// testKey identifies the test, because Process receives a copy of the test passed to BeforeTest.
+func testKey(t models.TestInterface) string {
+	return fmt.Sprintf("%s\n%s\n%d", t.GetFileName(), t.GetName(), t.GetLineNumber())
+}
+
+// NewOutput creates JUnit output, which writes report into fileName on Finalize call.
+func NewOutput(suiteName, fileName string) (*Output, error) {
+	absName, err := filepath.Abs(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mkDirAll(filepath.Dir(absName), 0o777)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Output{
+		fileName: fileName,
+		report: testSuites{
+			Name: suiteName,
+		},
+		suites:  map[string]*testSuite{},
+		started: map[string][]startedTest{},
+	}, nil
+}
+
+func (o *Output) BeforeTest(t models.TestInterface) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	now := timeNow()
+	switch t.GetStatus() {
+	case models.StatusSkipped:
+		o.addCase(t, now, 0).Skipped = &skipped{Message: "test was skipped"}
+	case models.StatusBroken:
+		o.addCase(t, now, 0).Skipped = &skipped{Message: "test was marked as broken"}
+	default:
+		key := testKey(t)
+		o.started[key] = append(o.started[key], startedTest{test: t, start: now})
+	}
+	return nil
+}
+
+func (o *Output) Process(t models.TestInterface, result *models.Result) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	end := timeNow()
+	start := end // if BeforeTest wasn't called for this test
+	key := testKey(t)
+	if started := o.started[key]; len(started) != 0 {
+		start = started[0].start
+		if len(started) == 1 {
+			delete(o.started, key)
+		} else {
+			o.started[key] = started[1:]
+		}
+	}
+
+	c := o.addCase(t, start, end.Sub(start))
+	if result.Passed() {
+		return nil
+	}
+
+	messages := make([]string, len(result.Errors))
+	for idx, err := range result.Errors {
+		messages[idx] = fmt.Sprintf("%d) %s", idx+1, err.Error())
+	}
+	c.Failure = &failure{
+		Message: result.Errors[0].Error(),
+		Type:    "AssertionError",
+		Text:    strings.Join(messages, "\n"),
+	}
+	method := t.GetMethod()
+	if t.GetGRPC() != nil {
+		method = "gRPC"
+	}
+	if t.GetWebSocket() != nil {
+		method = "WebSocket"
+	}
+	c.SystemOut = fmt.Sprintf("Request: %s %s%s\n%s\n\nResponse: %s\n%s",
+		method, result.Path, result.Query, result.RequestBody,
+		result.ResponseStatus, result.ResponseBody)
+	return nil
+}
+
+// Finalize writes the collected report to the file.
+// Started tests without processed result (their execution was interrupted by an error) are reported as errors.
+func (o *Output) Finalize() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.addInterrupted()
+
+	var total time.Duration
+	o.report.Tests, o.report.Failures, o.report.Errors, o.report.Skipped = 0, 0, 0, 0
+	for _, s := range o.report.Suites {
+		s.Tests, s.Failures, s.Errors, s.Skipped = len(s.Cases), 0, 0, 0
+		for _, c := range s.Cases {
+			if c.Failure != nil {
+				s.Failures++
+			}
+			if c.Error != nil {
+				s.Errors++
+			}
+			if c.Skipped != nil {
+				s.Skipped++
+			}
+		}
+		s.Time = formatDuration(s.duration)
+		s.Timestamp = s.start.UTC().Format("2006-01-02T15:04:05")
+
+		o.report.Tests += s.Tests
+		o.report.Failures += s.Failures
+		o.report.Errors += s.Errors
+		o.report.Skipped += s.Skipped
+		total += s.duration
+	}
+	o.report.Time = formatDuration(total)
+
+	b, err := xml.MarshalIndent(&o.report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(o.fileName, append([]byte(xml.Header), b...), 0o644)
+}
+
+// addInterrupted adds cases for started tests without result in the order of their start.
+func (o *Output) addInterrupted() {
+	var interrupted []startedTest
+	for _, started := range o.started {
+		interrupted = append(interrupted, started...)
+	}
+	o.started = map[string][]startedTest{}
+
+	sort.SliceStable(interrupted, func(i, j int) bool {
+		return interrupted[i].start.Before(interrupted[j].start)
+	})
+	for _, item := range interrupted {
+		o.addCase(item.test, item.start, 0).Error = &failure{
+			Message: "test execution was interrupted by an error",
+			Type:    "Error",
+		}
+	}
+}
+
+func (o *Output) addCase(t models.TestInterface, start time.Time, duration time.Duration) *testCase {
+	fileName := filepath.ToSlash(t.GetFileName())
+	suite, ok := o.suites[fileName]
+	if !ok {
+		suite = &testSuite{
+			Name:  fileName,
+			start: start,
+		}
+		o.suites[fileName] = suite
+		o.report.Suites = append(o.report.Suites, suite)
+	}
+	suite.duration += duration
+
+	c := &testCase{
+		Name:      t.GetName(),
+		ClassName: strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)),
+		File:      fileName,
+		Line:      t.GetLineNumber(),
+		Time:      formatDuration(duration),
+	}
+	suite.Cases = append(suite.Cases, c)
+	return c
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,74 @@
+package junit
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/testloader/yaml_file"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutput(t *testing.T) {
+	written := map[string]string{}
+	writeFile = func(name string, data []byte, perm os.FileMode) error {
+		written[name] = string(data)
+		return nil
+	}
+	mkDirAll = func(name string, perm os.FileMode) error {
+		return nil
+	}
+	now := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		now = now.Add(1500 * time.Millisecond)
+		return now
+	}
+
+	tests, err := yaml_file.NewLoader("testdata/testset1.yaml").Load()
+	require.NoError(t, err)
+	require.Len(t, tests, 5)
+
+	o, err := NewOutput("Gonkex", "report.xml")
+	require.NoError(t, err)
+
+	results := []*models.Result{
+		{
+			Path:               "/api/orders/1",
+			ResponseStatusCode: 200,
+			ResponseStatus:     "200 OK",
+			ResponseBody:       "{}",
+		},
+		{
+			Path:               "/api/orders",
+			RequestBody:        `{"id": 2}`,
+			ResponseStatusCode: 500,
+			ResponseStatus:     "500 Internal Server Error",
+			ResponseBody:       "oops",
+			Errors: []error{
+				errors.New("server responded with unexpected status code"),
+				errors.New("service response body comparison failed"),
+			},
+		},
+	}
+
+	// the last test isn't processed, because its execution failed
+	for idx, test := range tests {
+		require.NoError(t, o.BeforeTest(test))
+		if idx < len(results) {
+			// runner passes a copy of the test with substituted variables
+			clone := test.Clone()
+			results[idx].Test = clone
+			require.NoError(t, o.Process(clone, results[idx]))
+		}
+	}
+
+	require.NoError(t, o.Finalize())
+
+	expected, err := os.ReadFile("testdata/testset1_expected.xml")
+	require.NoError(t, err)
+	require.Equal(t, strings.ReplaceAll(string(expected), "\r\n", "\n"), written["report.xml"])
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"text/template"
+	"time"
 
 	"github.com/lansfy/gonkex/colorize"
 	"github.com/lansfy/gonkex/models"
@@ -118,6 +119,7 @@ func (o *Output) getTemplateFuncMap() template.FuncMap {
 		}
 	}
 	funcMap["inc"] = func(i int) int { return i + 1 }
+	funcMap["duration"] = func(d time.Duration) string { return d.Round(time.Microsecond).String() }
 	funcMap["prettify"] = func(body string) string {
 		if !o.opts.PrettyBody {
 			return body
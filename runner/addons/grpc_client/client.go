@@ -0,0 +1,216 @@
+package grpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lansfy/gonkex/runner"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Opts holds configuration of the gRPC client.
+type Opts struct {
+	// DescriptorSetFile is a path to the file with serialized FileDescriptorSet
+	// (created by protoc with --descriptor_set_out and --include_imports flags).
+	// If empty, descriptors are requested from the server with reflection service.
+	DescriptorSetFile string
+
+	// DialOptions are passed to grpc.NewClient. By default, connection without TLS is used.
+	DialOptions []grpc.DialOption
+
+	// Timeout limits the duration of each call (30 seconds by default).
+	Timeout time.Duration
+}
+
+// Client calls methods of gRPC service with requests and responses encoded as JSON.
+type Client struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+
+	// files contains descriptors from DescriptorSetFile (nil if server reflection is used)
+	files *protoregistry.Files
+
+	mutex   sync.Mutex
+	methods map[string]*methodInfo
+}
+
+type methodInfo struct {
+	desc protoreflect.MethodDescriptor
+	// types resolves message types of google.protobuf.Any fields
+	types *dynamicpb.Types
+}
+
+var _ runner.GRPCClient = (*Client)(nil)
+
+// New creates client for the gRPC service at the target address.
+func New(target string, opts *Opts) (*Client, error) {
+	if opts == nil {
+		opts = &Opts{}
+	}
+
+	c := &Client{
+		timeout: opts.Timeout,
+		methods: map[string]*methodInfo{},
+	}
+	if c.timeout == 0 {
+		c.timeout = 30 * time.Second
+	}
+
+	if opts.DescriptorSetFile != "" {
+		files, err := readDescriptorSet(opts.DescriptorSetFile)
+		if err != nil {
+			return nil, err
+		}
+		c.files = files
+	}
+
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	dialOptions = append(dialOptions, opts.DialOptions...)
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create gRPC connection to %s: %w", target, err)
+	}
+	c.conn = conn
+	return c, nil
+}
+
+// Close closes the connection to the service.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Invoke calls unary method described by the request.
+func (c *Client) Invoke(ctx context.Context, req *runner.GRPCRequest) (*runner.GRPCResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	fullMethod := strings.TrimPrefix(req.FullMethod, "/")
+	info, err := c.findMethod(ctx, fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	method := info.desc
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("streaming method %s is not supported", fullMethod)
+	}
+
+	in := dynamicpb.NewMessage(method.Input())
+	if strings.TrimSpace(req.Body) != "" {
+		if err := protojson.Unmarshal([]byte(req.Body), in); err != nil {
+			return nil, fmt.Errorf("decode request to %s: %w", method.Input().FullName(), err)
+		}
+	}
+
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(req.Metadata))
+	var header, trailer metadata.MD
+	out := dynamicpb.NewMessage(method.Output())
+	err = c.conn.Invoke(ctx, "/"+fullMethod, in, out, grpc.Header(&header), grpc.Trailer(&trailer))
+
+	resp := &runner.GRPCResponse{
+		Headers: map[string][]string{},
+	}
+	for _, md := range []metadata.MD{header, trailer} {
+		for key, values := range md {
+			resp.Headers[key] = append(resp.Headers[key], values...)
+		}
+	}
+
+	if err != nil {
+		st, ok := status.FromError(err)
+		if !ok {
+			return nil, err
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"code":    int(st.Code()),
+			"message": st.Message(),
+		})
+		resp.Code = int(st.Code())
+		resp.Status = fmt.Sprintf("%s: %s", st.Code(), st.Message())
+		resp.Body = string(body)
+		return resp, nil
+	}
+
+	body, err := protojson.MarshalOptions{Resolver: info.types}.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("encode response from %s: %w", method.Output().FullName(), err)
+	}
+	resp.Status = "OK"
+	resp.Body = string(body)
+	return resp, nil
+}
+
+func (c *Client) findMethod(ctx context.Context, fullMethod string) (*methodInfo, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if info, ok := c.methods[fullMethod]; ok {
+		return info, nil
+	}
+
+	pos := strings.LastIndex(fullMethod, "/")
+	if pos == -1 {
+		return nil, fmt.Errorf("invalid gRPC method %q", fullMethod)
+	}
+	serviceName := fullMethod[:pos]
+
+	files := c.files
+	if files == nil {
+		var err error
+		files, err = c.fetchWithReflection(ctx, serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("get descriptor of service %s with reflection: %w", serviceName, err)
+		}
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(fullMethod[pos+1:]))
+	if method == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", fullMethod[pos+1:], serviceName)
+	}
+
+	info := &methodInfo{
+		desc:  method,
+		types: dynamicpb.NewTypes(files),
+	}
+	c.methods[fullMethod] = info
+	return info, nil
+}
+
+func readDescriptorSet(fileName string) (*protoregistry.Files, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read descriptor set: %w", err)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(content, set); err != nil {
+		return nil, fmt.Errorf("decode descriptor set %s: %w", fileName, err)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("load descriptor set %s: %w", fileName, err)
+	}
+	return files, nil
+}
@@ -0,0 +1,266 @@
+package grpc_client
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/runner"
+	"github.com/lansfy/gonkex/testloader/yaml_file"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// usersFile describes service:
+//
+//	service UserService {
+//	  rpc GetUser(GetUserRequest) returns (User);
+//	}
+var usersFile = &descriptorpb.FileDescriptorProto{
+	Name:       proto.String("users/v1/users.proto"),
+	Package:    proto.String("users.v1"),
+	Syntax:     proto.String("proto3"),
+	Dependency: []string{"google/protobuf/timestamp.proto"},
+	MessageType: []*descriptorpb.DescriptorProto{
+		{
+			Name: proto.String("GetUserRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+			},
+		},
+		{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("created", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+			},
+		},
+	},
+	Service: []*descriptorpb.ServiceDescriptorProto{
+		{
+			Name: proto.String("UserService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{
+					Name:       proto.String("GetUser"),
+					InputType:  proto.String(".users.v1.GetUserRequest"),
+					OutputType: proto.String(".users.v1.User"),
+				},
+			},
+		},
+	},
+}
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type,
+	typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func usersRegistry(t *testing.T) *protoregistry.Files {
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+			usersFile,
+		},
+	})
+	require.NoError(t, err)
+	return files
+}
+
+// startServer starts UserService which returns user for any positive id.
+func startServer(t *testing.T, files *protoregistry.Files, withReflection bool) string {
+	desc, err := files.FindDescriptorByName("users.v1.UserService")
+	require.NoError(t, err)
+	method := desc.(protoreflect.ServiceDescriptor).Methods().ByName("GetUser")
+
+	handler := func(_ interface{}, ctx context.Context, dec func(interface{}) error,
+		_ grpc.UnaryServerInterceptor) (interface{}, error) {
+		in := dynamicpb.NewMessage(method.Input())
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		id := in.Get(method.Input().Fields().ByName("id")).Int()
+		if id <= 0 {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", first(md.Get("x-request-id"))))
+
+		out := dynamicpb.NewMessage(method.Output())
+		fields := method.Output().Fields()
+		out.Set(fields.ByName("id"), protoreflect.ValueOfInt64(id))
+		out.Set(fields.ByName("name"), protoreflect.ValueOfString("user"+first(md.Get("suffix"))))
+		created := dynamicpb.NewMessage(fields.ByName("created").Message())
+		created.Set(created.Descriptor().Fields().ByName("seconds"), protoreflect.ValueOfInt64(1700000000))
+		out.Set(fields.ByName("created"), protoreflect.ValueOfMessage(created))
+		return out, nil
+	}
+
+	srv := grpc.NewServer()
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "users.v1.UserService",
+		HandlerType: (*interface{})(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: "GetUser", Handler: handler}},
+	}, struct{}{})
+	if withReflection {
+		// reflection uses descriptors of the test instead of the global registry
+		rpb.RegisterServerReflectionServer(srv, reflection.NewServerV1(reflection.ServerOptions{
+			Services:           srv,
+			DescriptorResolver: files,
+		}))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+	return listener.Addr().String()
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+const tests = `
+- name: get user
+  grpc:
+    method: users.v1.UserService/GetUser
+    metadata:
+      suffix: "{{ $suffix }}"
+      x-request-id: "42"
+  variables:
+    suffix: "-1"
+  request: '{"id": "1"}'
+  response:
+    0: '{"id": "1", "name": "user-1", "created": "2023-11-14T22:13:20Z"}'
+  responseHeaders:
+    0:
+      x-request-id: "42"
+
+- name: unknown user
+  grpc:
+    method: /users.v1.UserService/GetUser
+  request: '{"id": "-1"}'
+  response:
+    5: '{"code": 5, "message": "user not found"}'
+
+- name: invalid request
+  grpc:
+    method: users.v1.UserService/GetUser
+  request: '{"unknown": 1}'
+  response:
+    0: '{}'
+
+- name: unknown method
+  grpc:
+    method: users.v1.UserService/DeleteUser
+  response:
+    0: '{}'
+`
+
+func runTests(t *testing.T, client runner.GRPCClient) map[string]string {
+	errs := map[string]string{}
+	r := runner.New(yaml_file.NewInMemoryLoader(map[string]string{"grpc.yaml": tests}, nil), &runner.RunnerOpts{
+		GRPCClient:   client,
+		OnFailPolicy: runner.PolicyContinue,
+		TestHandler: func(test models.TestInterface, executor runner.TestExecutor) (bool, error) {
+			result, err := executor(test)
+			switch {
+			case err != nil:
+				errs[test.GetName()] = err.Error()
+			case !result.Passed():
+				errs[test.GetName()] = result.Errors[0].Error()
+			}
+			return false, nil
+		},
+	})
+	require.NoError(t, r.Run())
+	return errs
+}
+
+func checkErrors(t *testing.T, errs map[string]string) {
+	require.Len(t, errs, 2, "%v", errs)
+	require.Contains(t, errs["invalid request"], "decode request to users.v1.GetUserRequest:")
+	require.Contains(t, errs["invalid request"], "unknown field \"unknown\"")
+	require.Equal(t, "call gRPC method users.v1.UserService/DeleteUser: method DeleteUser not found in service users.v1.UserService",
+		errs["unknown method"])
+}
+
+func Test_Client_Reflection(t *testing.T) {
+	addr := startServer(t, usersRegistry(t), true)
+
+	client, err := New(addr, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	checkErrors(t, runTests(t, client))
+}
+
+func Test_Client_DescriptorSet(t *testing.T) {
+	addr := startServer(t, usersRegistry(t), false)
+
+	content, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+			usersFile,
+		},
+	})
+	require.NoError(t, err)
+	fileName := filepath.Join(t.TempDir(), "users.protoset")
+	require.NoError(t, os.WriteFile(fileName, content, 0o600))
+
+	client, err := New(addr, &Opts{DescriptorSetFile: fileName})
+	require.NoError(t, err)
+	defer client.Close()
+
+	checkErrors(t, runTests(t, client))
+}
+
+func Test_Client_NoReflection(t *testing.T) {
+	addr := startServer(t, usersRegistry(t), false)
+
+	client, err := New(addr, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Invoke(context.Background(), &runner.GRPCRequest{FullMethod: "users.v1.UserService/GetUser"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "get descriptor of service users.v1.UserService with reflection:")
+}
+
+func Test_New_InvalidDescriptorSet(t *testing.T) {
+	_, err := New("127.0.0.1:1", &Opts{DescriptorSetFile: "not-exists.protoset"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "read descriptor set:")
+}
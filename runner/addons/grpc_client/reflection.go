@@ -0,0 +1,97 @@
+package grpc_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var errNoFiles = errors.New("server returned no file descriptors")
+
+// fetchWithReflection requests the file with the symbol (and all its dependencies)
+// from the server reflection service.
+func (c *Client) fetchWithReflection(ctx context.Context, symbol string) (*protoregistry.Files, error) {
+	stream, err := rpb.NewServerReflectionClient(c.conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.CloseSend()
+	}()
+
+	received := map[string]*descriptorpb.FileDescriptorProto{}
+	var ordered []*descriptorpb.FileDescriptorProto
+
+	request := &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}
+	for request != nil {
+		files, err := reflectionCall(stream, request)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if _, ok := received[file.GetName()]; !ok {
+				received[file.GetName()] = file
+				ordered = append(ordered, file)
+			}
+		}
+
+		// request dependencies which were not sent by the server
+		request = nil
+		for _, file := range ordered {
+			for _, dep := range file.GetDependency() {
+				if _, ok := received[dep]; ok {
+					continue
+				}
+				if known, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+					received[dep] = protodesc.ToFileDescriptorProto(known)
+					ordered = append(ordered, received[dep])
+					continue
+				}
+				request = &rpb.ServerReflectionRequest{
+					MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+				}
+				break
+			}
+			if request != nil {
+				break
+			}
+		}
+	}
+
+	return protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: ordered})
+}
+
+func reflectionCall(stream rpb.ServerReflection_ServerReflectionInfoClient,
+	request *rpb.ServerReflectionRequest) ([]*descriptorpb.FileDescriptorProto, error) {
+	if err := stream.Send(request); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, errors.New(errResp.GetErrorMessage())
+	}
+
+	var files []*descriptorpb.FileDescriptorProto
+	for _, content := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(content, file); err != nil {
+			return nil, fmt.Errorf("decode file descriptor: %w", err)
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, errNoFiles
+	}
+	return files, nil
+}
@@ -2,20 +2,159 @@ package runner
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"sync"
+
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/models"
+)
+
+const (
+	// ProtocolHTTP1 uses HTTP/1.1 (default).
+	ProtocolHTTP1 = "http1"
+	// ProtocolHTTP2 uses HTTP/2 over TLS.
+	ProtocolHTTP2 = "http2"
+	// ProtocolH2C uses HTTP/2 over plain TCP connection with prior knowledge (without upgrade).
+	ProtocolH2C = "h2c"
 )
 
-func newClient(proxyURL *url.URL) *http.Client {
+// ClientOpts holds configuration of the HTTP client used for requests to the tested service.
+type ClientOpts struct {
+	// CAFile is a path to PEM file with CA certificates. If set, the server certificate is verified
+	// with these certificates, otherwise the verification is disabled.
+	CAFile string
+
+	// CertFile and KeyFile are paths to PEM files with client certificate and its private key,
+	// which are presented to the server (mutual TLS).
+	CertFile string
+	KeyFile  string
+
+	// ServerName is used to verify the hostname on the server certificate and is sent as SNI.
+	ServerName string
+
+	// Protocol selects HTTP protocol: ProtocolHTTP1 (default), ProtocolHTTP2 or ProtocolH2C.
+	Protocol string
+}
+
+// merge returns options with values overridden by non-empty parameters of the test.
+func (o ClientOpts) merge(params models.ClientParams) ClientOpts {
+	if params == nil {
+		return o
+	}
+	override := func(value *string, newValue string) {
+		if newValue != "" {
+			*value = newValue
+		}
+	}
+	override(&o.CAFile, params.CAFile())
+	override(&o.CertFile, params.CertFile())
+	override(&o.KeyFile, params.KeyFile())
+	override(&o.ServerName, params.ServerName())
+	override(&o.Protocol, params.Protocol())
+	return o
+}
+
+func hasClientParams(params models.ClientParams) bool {
+	return params != nil && ClientOpts{}.merge(params) != ClientOpts{}
+}
+
+func newTLSConfig(opts *ClientOpts) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // Client is only used for testing.
+		ServerName:         opts.ServerName,
+	}
+
+	if opts.CAFile != "" {
+		content, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", opts.CAFile)
+		}
+		config.RootCAs = pool
+		config.InsecureSkipVerify = false
+	}
+
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, errors.New("client certificate requires both certFile and keyFile")
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+func newClient(proxyURL *url.URL, opts *ClientOpts) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // Client is only used for testing.
+		TLSClientConfig: tlsConfig,
 		Proxy:           http.ProxyURL(proxyURL),
 	}
+	if err := configureProtocol(transport, opts.Protocol); err != nil {
+		return nil, err
+	}
 
 	return &http.Client{
 		Transport: transport,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
+	}, nil
+}
+
+// clientsCache keeps HTTP clients created for different options, so connections are reused between tests.
+type clientsCache struct {
+	mutex   sync.Mutex
+	clients map[ClientOpts]HTTPClient
+}
+
+func (c *clientsCache) get(proxyURL *url.URL, opts *ClientOpts) (HTTPClient, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if client, ok := c.clients[*opts]; ok {
+		return client, nil
+	}
+	client, err := newClient(proxyURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	if c.clients == nil {
+		c.clients = map[ClientOpts]HTTPClient{}
+	}
+	c.clients[*opts] = client
+	return client, nil
+}
+
+// httpClient returns the client for requests of the test.
+func (r *Runner) httpClient(v models.TestInterface) (HTTPClient, error) {
+	params := v.GetClientParams()
+	if r.config.CustomClient != nil {
+		if hasClientParams(params) {
+			return nil, colorize.NewEntityError("section %s can't be used with custom HTTP client", "client")
+		}
+		return r.config.CustomClient, nil
+	}
+
+	opts := r.config.Client.merge(params)
+	client, err := r.clients.get(r.config.HTTPProxyURL, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("create HTTP client: %w", err)
 	}
+	return client, nil
 }
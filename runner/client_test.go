@@ -0,0 +1,215 @@
+package runner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/testloader/yaml_file"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newTestCert creates certificate signed by parent (self-signed if parent is nil) and stores it in dir.
+func newTestCert(t *testing.T, dir, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	c := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".pem"),
+		keyFile:  filepath.Join(dir, name+"-key.pem"),
+	}
+	require.NoError(t, os.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return c
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// protoHandler responds with common name of the client certificate and protocol of the request.
+func protoHandler(w http.ResponseWriter, r *http.Request) {
+	name := "none"
+	if r.TLS != nil && len(r.TLS.PeerCertificates) != 0 {
+		name = r.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprintf(w, `{"client": %q, "proto": %q}`, name, r.Proto)
+}
+
+func runClientTests(t *testing.T, opts *RunnerOpts, content string) (map[string]string, error) {
+	errs := map[string]string{}
+	opts.TestHandler = func(test models.TestInterface, executor TestExecutor) (bool, error) {
+		result, err := executor(test)
+		switch {
+		case err != nil:
+			errs[test.GetName()] = err.Error()
+		case !result.Passed():
+			errs[test.GetName()] = result.Errors[0].Error()
+		}
+		return false, nil
+	}
+	opts.OnFailPolicy = PolicyContinue
+	r := New(yaml_file.NewInMemoryLoader(map[string]string{"client.yaml": content}, nil), opts)
+	return errs, r.Run()
+}
+
+func Test_Client_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, dir, "ca", nil)
+	serverCert := newTestCert(t, dir, "127.0.0.1", ca)
+	client1 := newTestCert(t, dir, "client1", ca)
+	client2 := newTestCert(t, dir, "client2", ca)
+	otherCA := newTestCert(t, dir, "other-ca", nil)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(protoHandler))
+	srv.EnableHTTP2 = true
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate()},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	content := fmt.Sprintf(`
+- name: default client
+  method: GET
+  path: /
+  response:
+    200: '{"client": "client1", "proto": "HTTP/1.1"}'
+
+- name: other client certificate
+  method: GET
+  path: /
+  client:
+    certFile: %s
+    keyFile: %s
+    protocol: http2
+  response:
+    200: '{"client": "client2", "proto": "HTTP/2.0"}'
+
+- name: server certificate is verified
+  method: GET
+  path: /
+  client:
+    caFile: %s
+  response:
+    200: '{}'
+
+- name: certificate with key of other certificate
+  method: GET
+  path: /
+  client:
+    certFile: %s
+  response:
+    200: '{}'
+
+- name: unknown protocol
+  method: GET
+  path: /
+  client:
+    protocol: http3
+  response:
+    200: '{}'
+`, client2.certFile, client2.keyFile, otherCA.certFile, client2.certFile)
+
+	errs, err := runClientTests(t, &RunnerOpts{
+		Host: srv.URL,
+		Client: ClientOpts{
+			CAFile:   ca.certFile,
+			CertFile: client1.certFile,
+			KeyFile:  client1.keyFile,
+		},
+	}, content)
+	require.NoError(t, err)
+
+	require.Len(t, errs, 3)
+	require.Contains(t, errs["server certificate is verified"], "certificate signed by unknown authority")
+	require.Equal(t, "create HTTP client: load client certificate: tls: private key does not match public key",
+		errs["certificate with key of other certificate"])
+	require.Equal(t, "create HTTP client: unknown protocol 'http3' (allowed only http1, http2, h2c)",
+		errs["unknown protocol"])
+}
+
+func Test_Client_CustomClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(protoHandler))
+	defer srv.Close()
+
+	errs, err := runClientTests(t, &RunnerOpts{
+		Host:         srv.URL,
+		CustomClient: http.DefaultClient,
+	}, `
+- name: custom client
+  method: GET
+  path: /
+  response:
+    200: '{"client": "none", "proto": "HTTP/1.1"}'
+
+- name: client section with custom client
+  method: GET
+  path: /
+  client:
+    protocol: h2c
+  response:
+    200: '{}'
+`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"client section with custom client": "section 'client' can't be used with custom HTTP client",
+	}, errs)
+}
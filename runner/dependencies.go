@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lansfy/gonkex/checker"
+	"github.com/lansfy/gonkex/models"
+)
+
+func testKey(fileName, name string) string {
+	return fileName + "\n" + name
+}
+
+// selectTests marks tests which must not be executed because of focus status, tags expressions
+// or the filter function (if not nil) as skipped.
+// Tests which selected tests depend on are selected too, even if they don't match the tags expressions.
+func (r *Runner) selectTests(tests []models.TestInterface, hasFocused bool, filter func(models.TestInterface) bool) error {
+	expressions, err := r.tagsExpressions()
+	if err != nil {
+		return err
+	}
+
+	byKey := map[string][]models.TestInterface{}
+	for _, t := range tests {
+		key := testKey(t.GetFileName(), t.GetName())
+		byKey[key] = append(byKey[key], t)
+	}
+
+	selected := map[models.TestInterface]bool{}
+	var queue []models.TestInterface
+	for _, t := range tests {
+		switch t.GetStatus() {
+		case models.StatusBroken, models.StatusSkipped:
+			continue
+		case models.StatusNone:
+			if hasFocused {
+				continue
+			}
+		}
+		if matchTags(t, expressions) && (filter == nil || filter(t)) {
+			selected[t] = true
+			queue = append(queue, t)
+		}
+	}
+
+	// pull in dependencies of selected tests
+	for len(queue) != 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, name := range t.GetDependencies() {
+			for _, dep := range byKey[testKey(t.GetFileName(), name)] {
+				status := dep.GetStatus()
+				if selected[dep] || status == models.StatusBroken || status == models.StatusSkipped {
+					continue
+				}
+				selected[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	for _, t := range tests {
+		switch {
+		case t.GetStatus() == models.StatusBroken:
+			// do nothing
+		case !selected[t]:
+			t.SetStatus(models.StatusSkipped)
+		case hasFocused:
+			t.SetStatus(models.StatusFocus)
+		}
+	}
+	return nil
+}
+
+type dependencyResult int
+
+const (
+	dependencyPassed dependencyResult = iota
+	dependencyFailed
+	dependencySkipped
+)
+
+// dependencyTracker remembers results of executed tests
+// to skip tests whose dependencies were not passed.
+type dependencyTracker struct {
+	results map[string]dependencyResult
+}
+
+func newDependencyTracker() *dependencyTracker {
+	return &dependencyTracker{
+		results: map[string]dependencyResult{},
+	}
+}
+
+// register saves result of the test.
+func (d *dependencyTracker) register(t models.TestInterface, err error) {
+	key := testKey(t.GetFileName(), t.GetName())
+	switch {
+	case t.GetStatus() == models.StatusSkipped || errors.Is(err, checker.ErrTestSkipped):
+		d.results[key] = dependencySkipped
+	case err != nil || t.GetStatus() != models.StatusNone:
+		d.results[key] = dependencyFailed
+	default:
+		d.results[key] = dependencyPassed
+	}
+}
+
+// check returns the reason why the test can't be executed, or nil if all its dependencies passed.
+func (d *dependencyTracker) check(t models.TestInterface) error {
+	for _, name := range t.GetDependencies() {
+		result, executed := d.results[testKey(t.GetFileName(), name)]
+		switch {
+		case !executed:
+			return fmt.Errorf("dependency '%s' was not executed", name)
+		case result == dependencySkipped:
+			return fmt.Errorf("dependency '%s' was skipped", name)
+		case result == dependencyFailed:
+			return fmt.Errorf("dependency '%s' failed", name)
+		}
+	}
+	return nil
+}
+
+// skipWithReason adds the reason to the error of the skipped test.
+func skipWithReason(executor TestExecutor, reason error) TestExecutor {
+	return func(t models.TestInterface) (*models.Result, error) {
+		result, err := executor(t)
+		if errors.Is(err, checker.ErrTestSkipped) {
+			err = fmt.Errorf("%w: %s", err, reason.Error())
+		}
+		return result, err
+	}
+}
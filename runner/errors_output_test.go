@@ -23,6 +23,8 @@ const showOnScreen = false // get output on screen to debug colors
 var (
 	// This regex matches :[port]/ after 127.0.0.1
 	portRegexp = regexp.MustCompile(`127\.0\.0\.1:\d+`)
+	// Request timings line in terminal output
+	timeRegexp = regexp.MustCompile(`(?m)^       Time: .*$`)
 	// Http date regexp
 	dateRegexp = regexp.MustCompile("(Mon|Tue|Wed|Thu|Fri|Sat|Sun), ([0-3][0-9]) (Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) ([0-9]{4}) ([01][0-9]|2[0-3])(:[0-5][0-9]){2} GMT")
 )
@@ -59,6 +61,7 @@ func normalize(s string) string {
 	s = strings.ReplaceAll(s, "\r\n", "\n")
 	s = dateRegexp.ReplaceAllString(s, "Sat, 1 Dec 2024 00:00:00 GMT")
 	s = portRegexp.ReplaceAllString(s, "127.0.0.1:80")
+	s = timeRegexp.ReplaceAllString(s, "       Time: 1ms (DNS: 0s, connect: 0s, TLS: 0s, TTFB: 1ms)")
 	return s
 }
 
@@ -74,7 +77,7 @@ func Test_Error_Examples(t *testing.T) {
 	initErrorServer()
 	server := httptest.NewServer(nil)
 
-	for caseID := 1; caseID <= 7; caseID++ {
+	for caseID := 1; caseID <= 8; caseID++ {
 		t.Run(fmt.Sprintf("case%d", caseID), func(t *testing.T) {
 			expected, err := os.ReadFile(fmt.Sprintf("testdata/errors-example/case%d_output.txt", caseID))
 			require.NoError(t, err)
@@ -0,0 +1,201 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/lansfy/gonkex/cmd_runner"
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/mocks"
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/variables"
+)
+
+// executeFileHook performs actions declared in beforeAll/afterAll sections of the test file.
+func (r *Runner) executeFileHook(name string, hook models.FileHook) error {
+	wrap := func(err error) error {
+		return colorize.NewEntityError("section %s", name).WithSubError(err)
+	}
+
+	if err := r.loadFileMocks(hook); err != nil {
+		return wrap(err)
+	}
+
+	if r.config.DB != nil && len(hook.Fixtures()) != 0 {
+		fixtures := make([]string, len(hook.Fixtures()))
+		for idx, f := range hook.Fixtures() {
+			fixtures[idx] = r.config.Variables.Substitute(f)
+		}
+		err := r.config.DB.LoadFixtures(r.config.FixturesDir, fixtures)
+		if err != nil {
+			return wrap(fmt.Errorf("load fixtures %v: %w", fixtures, err))
+		}
+	}
+
+	script := hook.Script()
+	if script.CmdLine() != "" {
+		script = &hookScript{script, r.config.Variables.Substitute(script.CmdLine())}
+		_, err := cmd_runner.ExecuteScript(script)
+		if err != nil {
+			return wrap(err)
+		}
+	}
+	return nil
+}
+
+// hookScript is the script of beforeAll/afterAll section with substituted variables.
+type hookScript struct {
+	models.Script
+	cmdLine string
+}
+
+func (s *hookScript) CmdLine() string {
+	return s.cmdLine
+}
+
+// loadFileMocks loads mocks declared in beforeAll section. Definitions and state of these mocks
+// are kept for all tests of the file, which don't declare their own mocks for the same services.
+func (r *Runner) loadFileMocks(hook models.FileHook) error {
+	raw := hook.ServiceMocks(r.config.Variables.Substitute)
+	if r.config.Mocks == nil || r.isolated || len(raw) == 0 {
+		return nil
+	}
+
+	r.resetMocks()
+	definitions := fileMocksLoader{}
+	if err := r.config.MocksLoader.LoadRawDefinition(definitions, raw); err != nil {
+		return err
+	}
+	for name, def := range definitions {
+		if err := r.config.Mocks.SetServiceDefinition(name, def); err != nil {
+			return err
+		}
+	}
+	r.fileMocks = definitions
+	return nil
+}
+
+// beforeAllMocks returns definitions of mocks declared in beforeAll section of the test file.
+func beforeAllMocks(t models.TestInterface) map[string]interface{} {
+	if hook := t.BeforeAll(); hook != nil {
+		return hook.ServiceMocks(func(s string) string { return s })
+	}
+	return nil
+}
+
+// endFileMocks checks calls of mocks loaded by beforeAll section and drops their definitions.
+func (r *Runner) endFileMocks() []error {
+	var errs []error
+	for name, def := range r.fileMocks {
+		for _, err := range def.EndRunningContext(false) {
+			errs = append(errs, colorize.NewEntityError("mock %s", name).WithSubError(err))
+		}
+	}
+	r.fileMocks = nil
+	return errs
+}
+
+// resetTestMocks prepares mocks for the next test. Mocks loaded by beforeAll section get
+// their definitions back with the current state, other mocks get default definitions.
+func (r *Runner) resetTestMocks() {
+	for _, name := range r.config.Mocks.GetNames() {
+		service := r.config.Mocks.Service(name)
+		if def, ok := r.fileMocks[name]; ok {
+			service.SetDefinition(def)
+			service.ResetJournal()
+			continue
+		}
+		service.ResetRunningContext()
+		service.ResetDefinition()
+	}
+}
+
+// endTestMocks finalizes the running context of mocks after the test. Calls of mocks loaded
+// by beforeAll section are checked after the last test of the file, if the test doesn't replace them.
+func (r *Runner) endTestMocks(v models.TestInterface) []error {
+	intermediate := v.ServiceMocksParams().SkipMocksResetAfterTest()
+	var errs []error
+	for _, name := range r.config.Mocks.GetNames() {
+		_, shared := r.fileMocks[name]
+		_, own := v.ServiceMocks()[name]
+		errs = append(errs, r.config.Mocks.Service(name).EndRunningContext(intermediate || (shared && !own))...)
+	}
+	return errs
+}
+
+// fileMocksLoader collects definitions of mocks declared in beforeAll section.
+type fileMocksLoader map[string]*mocks.Definition
+
+func (l fileMocksLoader) SetServiceDefinition(serviceName string, def *mocks.Definition) error {
+	l[serviceName] = def
+	return nil
+}
+
+// fileHooksState tracks execution of beforeAll/afterAll sections for the current test file.
+type fileHooksState struct {
+	started  bool
+	afterAll models.FileHook
+	// parent is the variables scope of the runner, which is restored after the file.
+	parent variables.Variables
+}
+
+// before executes beforeAll section before the first executed test of the file.
+// Variables of the section are visible only in tests of the file.
+func (s *fileHooksState) before(r *Runner, t models.TestInterface) error {
+	if s.started || t.GetStatus() != models.StatusNone {
+		return nil
+	}
+	s.started = true
+	s.afterAll = t.AfterAll()
+	hook := t.BeforeAll()
+	if hook == nil {
+		return nil
+	}
+
+	s.parent = r.config.Variables
+	scope := variables.NewScope(s.parent)
+	scope.Merge(hook.GetVariables())
+	r.config.Variables = &fileScope{Variables: scope, parent: s.parent}
+	return r.executeFileHook("beforeAll", hook)
+}
+
+// after executes afterAll section if at least one test of the file was started,
+// checks calls of mocks loaded by beforeAll section and drops variables of the file.
+func (s *fileHooksState) after(r *Runner) []error {
+	hook := s.afterAll
+	started := s.started
+	s.started = false
+	s.afterAll = nil
+	if !started {
+		return nil
+	}
+
+	var errs []error
+	if hook != nil {
+		if err := r.executeFileHook("afterAll", hook); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, r.endFileMocks()...)
+	if s.parent != nil {
+		r.config.Variables = s.parent
+		s.parent = nil
+	}
+	return errs
+}
+
+// fileScope contains variables of beforeAll section on top of the runner variables.
+// Variables set by tests are written to the runner variables too, so they outlive the file.
+type fileScope struct {
+	variables.Variables
+	parent variables.Variables
+}
+
+func (s *fileScope) Set(name, value string) {
+	s.parent.Set(name, value)
+	s.Variables.Set(name, value)
+}
+
+func (s *fileScope) Merge(vars map[string]string) {
+	s.parent.Merge(vars)
+	s.Variables.Merge(vars)
+}
@@ -6,13 +6,28 @@ import (
 
 const filterFlagName = "gonkex-filter"
 const allureDirFlagName = "gonkex-allure-dir"
+const junitFileFlagName = "gonkex-junit-file"
+const tagsFlagName = "gonkex-tags"
+const recordFlagName = "gonkex-record"
+const recordPatternsFlagName = "gonkex-record-patterns"
+const updateFlagName = "gonkex-update"
 
 var filterFlag string
 var allureDirFlag string
+var junitFileFlag string
+var tagsFlag string
+var recordFlag bool
+var recordPatternsFlag bool
+var updateFlag bool
 
 // RegisterFlags registers command-line flags for the Gonkex testing framework:
 // * "gonkex-filter" flag that allows users to filter which test files are executed during a test run.
 // * "gonkex-allure-dir" flag which enable allure report and set folder for execution's result.
+// * "gonkex-junit-file" flag which enable JUnit XML report and set file name for it.
+// * "gonkex-tags" flag that allows users to select tests by tags expression.
+// * "gonkex-record" flag which enable record mode: responses of tests without expected response are written to test files.
+// * "gonkex-record-patterns" flag which replace UUIDs and timestamps in recorded responses with patterns.
+// * "gonkex-update" flag which rewrite files from responseFile section with actual responses.
 //
 // Usage: in test file add next code
 //
@@ -27,6 +42,10 @@ var allureDirFlag string
 //	go test -gonkex-filter=mytest.yaml      // Run only tests in file mytest.yaml
 //	go test -gonkex-filter=mytest           // Run all files which has "mytest" in path or name
 //	go test -gonkex-allure-dir=testresult   // Generate allure report after tests in "testresult" folder
+//	go test -gonkex-junit-file=report.xml   // Generate JUnit XML report after tests in "report.xml" file
+//	go test -gonkex-tags="smoke && !slow"   // Run only tests with "smoke" tag and without "slow" tag
+//	go test -gonkex-record                  // Record responses of tests without expected response
+//	go test -gonkex-update                  // Update files with expected responses (responseFile section)
 //
 // The flags values is stored in the package-level variables and applied
 // to the test loader when non-empty, allowing users customize execution via "go test" flags.
@@ -37,4 +56,19 @@ func RegisterFlags() {
 	if flag.Lookup(allureDirFlagName) == nil {
 		flag.StringVar(&allureDirFlag, allureDirFlagName, "", "if non-empty, gonkex will create allure report in specified folder.")
 	}
+	if flag.Lookup(junitFileFlagName) == nil {
+		flag.StringVar(&junitFileFlag, junitFileFlagName, "", "if non-empty, gonkex will create JUnit XML report in specified file.")
+	}
+	if flag.Lookup(tagsFlagName) == nil {
+		flag.StringVar(&tagsFlag, tagsFlagName, "", "if non-empty, gonkex will run only tests which match this tags expression.")
+	}
+	if flag.Lookup(recordFlagName) == nil {
+		flag.BoolVar(&recordFlag, recordFlagName, false, "if true, gonkex will write responses of tests without expected response to test files.")
+	}
+	if flag.Lookup(recordPatternsFlagName) == nil {
+		flag.BoolVar(&recordPatternsFlag, recordPatternsFlagName, false, "if true, gonkex will replace UUIDs and timestamps in recorded responses with patterns.")
+	}
+	if flag.Lookup(updateFlagName) == nil {
+		flag.BoolVar(&updateFlag, updateFlagName, false, "if true, gonkex will rewrite files from responseFile section with actual responses.")
+	}
 }
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+)
+
+// defaultGRPCTimeout is used as deadline of the call if timeout of the grpc section is not set.
+const defaultGRPCTimeout = 30 * time.Second
+
+// GRPCRequest describes the call of gRPC method.
+type GRPCRequest struct {
+	FullMethod string            // Full name of the method, e.g. "package.Service/Method"
+	Metadata   map[string]string // Metadata sent with the request
+	Body       string            // Request message encoded as JSON
+}
+
+// GRPCResponse contains the result of gRPC call.
+type GRPCResponse struct {
+	Code    int                 // gRPC status code (0 means OK)
+	Status  string              // Human-readable status, e.g. "NotFound: user not found"
+	Body    string              // Response message encoded as JSON (or status details for failed call)
+	Headers map[string][]string // Response headers and trailers
+}
+
+// GRPCClient performs calls of gRPC methods described by tests.
+// The context passed to Invoke has the deadline of the call, which must be respected by the client.
+// Implementation based on google.golang.org/grpc is provided by the grpc_client addon.
+type GRPCClient interface {
+	Invoke(ctx context.Context, req *GRPCRequest) (*GRPCResponse, error)
+}
+
+func makeGRPCRequest(client GRPCClient, v models.TestInterface) (*models.Result, error) {
+	if client == nil {
+		return nil, errors.New("gRPC client is not configured, set GRPCClient in runner options")
+	}
+
+	params := v.GetGRPC()
+	timeout := params.Timeout()
+	if timeout <= 0 {
+		timeout = defaultGRPCTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := client.Invoke(ctx, &GRPCRequest{
+		FullMethod: params.FullMethod(),
+		Metadata:   params.Metadata(),
+		Body:       v.GetRequest(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("call gRPC method %s: %w", params.FullMethod(), err)
+	}
+
+	// metadata keys are lowercase, but response headers are checked as HTTP headers
+	headers := http.Header{}
+	for key, values := range resp.Headers {
+		for _, value := range values {
+			headers.Add(key, value)
+		}
+	}
+
+	return &models.Result{
+		Path:                params.FullMethod(),
+		RequestBody:         v.GetRequest(),
+		ResponseStatusCode:  resp.Code,
+		ResponseStatus:      resp.Status,
+		ResponseContentType: "application/json",
+		ResponseHeaders:     headers,
+		ResponseBody:        resp.Body,
+		Timings:             models.Timings{Total: time.Since(start)},
+		Test:                v,
+	}, nil
+}
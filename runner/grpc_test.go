@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type grpcClientFunc func(ctx context.Context, req *GRPCRequest) (*GRPCResponse, error)
+
+func (f grpcClientFunc) Invoke(ctx context.Context, req *GRPCRequest) (*GRPCResponse, error) {
+	return f(ctx, req)
+}
+
+func fakeUserService(_ context.Context, req *GRPCRequest) (*GRPCResponse, error) {
+	if req.FullMethod != "users.v1.UserService/GetUser" {
+		return nil, errors.New("connection refused")
+	}
+	if req.Metadata["authorization"] != "secret" {
+		return &GRPCResponse{
+			Code:   16,
+			Status: "Unauthenticated",
+			Body:   `{"code": 16, "message": "no token"}`,
+		}, nil
+	}
+	return &GRPCResponse{
+		Code:   0,
+		Status: "OK",
+		Body:   fmt.Sprintf(`{"user": %s}`, req.Body),
+	}, nil
+}
+
+func Test_GRPC(t *testing.T) {
+	content := `
+- name: successful call
+  grpc:
+    method: users.v1.UserService/GetUser
+    metadata:
+      authorization: "{{ $token }}"
+  variables:
+    token: secret
+  request: '{"id": 1}'
+  response:
+    0: '{"user": {"id": 1}}'
+  variables_to_set:
+    0:
+      userID: user.id
+
+- name: call with variable from previous response
+  grpc:
+    method: users.v1.UserService/GetUser
+    metadata:
+      authorization: secret
+  request: '{"id": {{ $userID }}}'
+  response:
+    0: '{"user": {"id": 1}}'
+
+- name: error status
+  grpc:
+    method: users.v1.UserService/GetUser
+  request: '{"id": 1}'
+  response:
+    16: '{"code": 16, "message": "$matchRegexp(^no)"}'
+
+- name: unexpected status
+  grpc:
+    method: users.v1.UserService/GetUser
+  response:
+    0: '{}'
+
+- name: call failed
+  grpc:
+    method: users.v1.UserService/DeleteUser
+  response:
+    0: '{}'
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{
+		GRPCClient: grpcClientFunc(fakeUserService),
+	}, content)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"unexpected status": "server responded with unexpected 'status code':\n     expected: 0\n       actual: 16",
+		"call failed":       "call gRPC method users.v1.UserService/DeleteUser: connection refused",
+	}, errs)
+}
+
+func Test_GRPC_NoClient(t *testing.T) {
+	errs, err := runClientTests(t, &RunnerOpts{}, `
+- name: call without client
+  grpc:
+    method: users.v1.UserService/GetUser
+  response:
+    0: '{}'
+`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"call without client": "gRPC client is not configured, set GRPCClient in runner options",
+	}, errs)
+}
+
+func Test_GRPC_Timeout(t *testing.T) {
+	var deadline time.Duration
+	hungService := func(ctx context.Context, _ *GRPCRequest) (*GRPCResponse, error) {
+		if d, ok := ctx.Deadline(); ok {
+			deadline = time.Until(d)
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	start := time.Now()
+	errs, err := runClientTests(t, &RunnerOpts{
+		GRPCClient: grpcClientFunc(hungService),
+	}, `
+- name: hung call
+  grpc:
+    method: users.v1.UserService/GetUser
+    timeout: 100ms
+  response:
+    0: '{}'
+`)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, map[string]string{
+		"hung call": "call gRPC method users.v1.UserService/GetUser: context deadline exceeded",
+	}, errs)
+	require.LessOrEqual(t, deadline, 100*time.Millisecond)
+}
+
+func Test_GRPC_DefaultTimeout(t *testing.T) {
+	var deadline time.Duration
+	service := func(ctx context.Context, _ *GRPCRequest) (*GRPCResponse, error) {
+		d, ok := ctx.Deadline()
+		require.True(t, ok)
+		deadline = time.Until(d)
+		return &GRPCResponse{Body: "{}"}, nil
+	}
+
+	errs, err := runClientTests(t, &RunnerOpts{
+		GRPCClient: grpcClientFunc(service),
+	}, `
+- name: call with default timeout
+  grpc:
+    method: users.v1.UserService/GetUser
+  response:
+    0: '{}'
+`)
+	require.NoError(t, err)
+	require.Empty(t, errs)
+	require.Greater(t, deadline, defaultGRPCTimeout-time.Second)
+	require.LessOrEqual(t, deadline, defaultGRPCTimeout)
+}
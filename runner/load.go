@@ -0,0 +1,352 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/variables"
+)
+
+// LoadOpts holds configuration options for the load mode.
+type LoadOpts struct {
+	// Concurrency is the number of virtual users. Every user executes the selected tests
+	// in the order of declaration again and again, and has its own variables scope.
+	// Default value is 1.
+	Concurrency int
+
+	// Rate limits the total number of requests per second made by all users (0 means no limit).
+	Rate float64
+
+	// Duration limits the time of the load run.
+	Duration time.Duration
+
+	// Iterations limits the number of runs of all selected tests made by every user.
+	// At least one of Duration and Iterations must be set.
+	Iterations int
+}
+
+// LoadStats contains statistics of requests made in the load mode.
+type LoadStats struct {
+	Name       string
+	Requests   int
+	Errors     int
+	FirstError error // first error occurred, nil if all requests were successful
+
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// ErrorRate returns the share of failed requests (from 0 to 1).
+func (s *LoadStats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+// LoadReport contains the results of the load run.
+type LoadReport struct {
+	Duration time.Duration // Wall time of the run
+	Total    LoadStats     // Statistics for all requests
+	Tests    []LoadStats   // Statistics for every test in the order of execution
+}
+
+// Throughput returns the number of requests per second.
+func (r *LoadReport) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Total.Requests) / r.Duration.Seconds()
+}
+
+// Print writes the report as a table.
+func (r *LoadReport) Print(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Load results: %d requests in %s (%.1f req/s), errors: %d (%.2f%%)\n\n",
+		r.Total.Requests, r.Duration.Round(time.Millisecond), r.Throughput(),
+		r.Total.Errors, r.Total.ErrorRate()*100)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Test\tRequests\tErrors\tMin\tMean\tP50\tP90\tP95\tP99\tMax")
+	for _, s := range append(r.Tests, r.Total) {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Name, s.Requests, s.Errors, roundDuration(s.Min), roundDuration(s.Mean), roundDuration(s.P50),
+			roundDuration(s.P90), roundDuration(s.P95), roundDuration(s.P99), roundDuration(s.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, s := range r.Tests {
+		if s.FirstError != nil {
+			_, _ = fmt.Fprintf(w, "\nFirst error of test '%s': %s\n", s.Name, s.FirstError.Error())
+		}
+	}
+	return nil
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+// loadSamples collects results of requests for one test.
+type loadSamples struct {
+	latencies  []time.Duration
+	errors     int
+	firstError error
+}
+
+func (s *loadSamples) add(latency time.Duration, err error) {
+	s.latencies = append(s.latencies, latency)
+	if err != nil {
+		s.errors++
+		if s.firstError == nil {
+			s.firstError = err
+		}
+	}
+}
+
+func (s *loadSamples) merge(other *loadSamples) {
+	s.latencies = append(s.latencies, other.latencies...)
+	s.errors += other.errors
+	if s.firstError == nil {
+		s.firstError = other.firstError
+	}
+}
+
+func (s *loadSamples) stats(name string) LoadStats {
+	result := LoadStats{
+		Name:       name,
+		Requests:   len(s.latencies),
+		Errors:     s.errors,
+		FirstError: s.firstError,
+	}
+	if len(s.latencies) == 0 {
+		return result
+	}
+
+	sorted := append([]time.Duration{}, s.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, l := range sorted {
+		sum += l
+	}
+	result.Min = sorted[0]
+	result.Max = sorted[len(sorted)-1]
+	result.Mean = sum / time.Duration(len(sorted))
+	result.P50 = percentile(sorted, 50)
+	result.P90 = percentile(sorted, 90)
+	result.P95 = percentile(sorted, 95)
+	result.P99 = percentile(sorted, 99)
+	return result
+}
+
+// percentile returns the value of the percentile p using nearest-rank method (sorted must be non-empty).
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// selectLoadTests returns tests which can be executed in the load mode.
+// Tests which use fixtures, database checks or mocks are excluded, because they can't be
+// executed concurrently.
+func (r *Runner) selectLoadTests() ([]models.TestInterface, error) {
+	if filterFlag != "" {
+		setStringFilter(r.loader, filterFlag)
+	}
+
+	tests, err := r.loader.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.selectTests(tests, checkHasFocused(tests), nil); err != nil {
+		return nil, err
+	}
+
+	var selected []models.TestInterface
+	for _, t := range tests {
+		status := t.GetStatus()
+		if (status == models.StatusNone || status == models.StatusFocus) && !usesSharedResources(t) {
+			selected = append(selected, t)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("no tests for load mode: all tests were filtered out or use fixtures, database checks or mocks")
+	}
+	return selected, nil
+}
+
+// RunLoad executes the selected tests repeatedly with the configured concurrency and rate,
+// checks responses and returns statistics of the run.
+// Outputs are not called in the load mode, failed requests are counted as errors.
+func (r *Runner) RunLoad(opts *LoadOpts) (*LoadReport, error) {
+	o := LoadOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Duration <= 0 && o.Iterations <= 0 {
+		return nil, errors.New("load mode requires duration or number of iterations")
+	}
+	if o.Concurrency < 1 {
+		o.Concurrency = 1
+	}
+
+	tests, err := r.selectLoadTests()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	var closeOnce sync.Once
+	stop := func() {
+		closeOnce.Do(func() { close(done) })
+	}
+
+	if o.Duration > 0 {
+		timer := time.AfterFunc(o.Duration, stop)
+		defer timer.Stop()
+	}
+
+	var tokens <-chan time.Time
+	if o.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / o.Rate))
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	start := time.Now()
+	samples := make([][]*loadSamples, o.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < o.Concurrency; i++ {
+		samples[i] = make([]*loadSamples, len(tests))
+		for idx := range tests {
+			samples[i][idx] = &loadSamples{}
+		}
+
+		wg.Add(1)
+		go func(results []*loadSamples) {
+			defer wg.Done()
+			r.loadWorker().runLoadIterations(tests, &o, tokens, done, results)
+		}(samples[i])
+	}
+	wg.Wait()
+	stop()
+
+	report := &LoadReport{
+		Duration: time.Since(start),
+	}
+	total := &loadSamples{}
+	for idx, t := range tests {
+		merged := &loadSamples{}
+		for i := range samples {
+			merged.merge(samples[i][idx])
+		}
+		total.merge(merged)
+		report.Tests = append(report.Tests, merged.stats(t.GetName()))
+	}
+	report.Total = total.stats("Total")
+	return report, nil
+}
+
+// loadWorker creates a runner for a single virtual user of the load mode.
+func (r *Runner) loadWorker() *Runner {
+	w := &Runner{
+		loader:   r.loader,
+		checkers: r.checkers,
+		config:   r.config,
+		isolated: true,
+		clients:  r.clients,
+	}
+	w.config.Variables = variables.NewScope(r.config.Variables)
+	return w
+}
+
+func (r *Runner) runLoadIterations(tests []models.TestInterface, opts *LoadOpts,
+	tokens <-chan time.Time, done <-chan struct{}, results []*loadSamples) {
+	for iteration := 0; opts.Iterations <= 0 || iteration < opts.Iterations; iteration++ {
+		for idx, t := range tests {
+			if tokens != nil {
+				select {
+				case <-tokens:
+				case <-done:
+					return
+				}
+			}
+
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			start := time.Now()
+			result, err := r.executeLoadRequest(t)
+			latency := time.Since(start)
+			if err == nil {
+				latency = result.Timings.Total
+				if !result.Passed() {
+					err = result.Errors[0]
+				}
+			}
+			results[idx].add(latency, err)
+		}
+	}
+}
+
+// executeLoadRequest makes the request of the test and checks the response.
+// Unlike executeTest it doesn't support retries, pauses, scripts, fixtures and mocks.
+func (r *Runner) executeLoadRequest(v models.TestInterface) (*models.Result, error) {
+	if err := r.checkers.BeforeTest(v); err != nil {
+		return nil, err
+	}
+
+	r.config.Variables.Merge(v.GetCombinedVariables())
+	v = v.Clone()
+	v.ApplyVariables(r.config.Variables.Substitute)
+
+	if len(v.GetSteps()) != 0 {
+		// the whole scenario is measured as a single request, the steps are checked by runSteps
+		return r.runSteps(v)
+	}
+
+	result, err := r.makeRequest(v)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, errs := r.setVariablesFromResponse(v, result)
+	if len(errs) != 0 {
+		result.Errors = append(result.Errors, errs...)
+		return result, nil
+	}
+	if changed {
+		v.ApplyVariables(r.config.Variables.Substitute)
+	}
+
+	errs, err = r.checkers.Check(v, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Errors = append(result.Errors, errs...)
+	return result, nil
+}
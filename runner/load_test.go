@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/testloader/yaml_file"
+
+	"github.com/stretchr/testify/require"
+)
+
+type loadServer struct {
+	mutex   sync.Mutex
+	counter int
+	tokens  map[string]bool
+}
+
+func (s *loadServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rw.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/login":
+		s.counter++
+		token := fmt.Sprintf("token%d", s.counter)
+		s.tokens[token] = true
+		_, _ = fmt.Fprintf(rw, `{"token": %q}`, token)
+	default:
+		if !s.tokens[r.Header.Get("Authorization")] {
+			rw.WriteHeader(http.StatusUnauthorized)
+			_, _ = rw.Write([]byte(`{"status": "unauthorized"}`))
+			return
+		}
+		_, _ = rw.Write([]byte(`{"status": "ok"}`))
+	}
+}
+
+func newLoadRunner(t *testing.T) *Runner {
+	srv := httptest.NewServer(&loadServer{tokens: map[string]bool{}})
+	t.Cleanup(srv.Close)
+	return New(yaml_file.NewLoader("testdata/load"), &RunnerOpts{Host: srv.URL})
+}
+
+func Test_RunLoad_Iterations(t *testing.T) {
+	report, err := newLoadRunner(t).RunLoad(&LoadOpts{
+		Concurrency: 3,
+		Iterations:  5,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, report.Tests, 3)
+	for i, name := range []string{"login", "get profile", "always fails"} {
+		require.Equal(t, name, report.Tests[i].Name)
+		require.Equal(t, 15, report.Tests[i].Requests)
+	}
+	require.Equal(t, 0, report.Tests[0].Errors)
+	require.Equal(t, 0, report.Tests[1].Errors)
+	require.Equal(t, 15, report.Tests[2].Errors)
+	require.Error(t, report.Tests[2].FirstError)
+
+	require.Equal(t, "Total", report.Total.Name)
+	require.Equal(t, 45, report.Total.Requests)
+	require.Equal(t, 15, report.Total.Errors)
+	require.InDelta(t, 1.0/3, report.Total.ErrorRate(), 0.0001)
+	require.LessOrEqual(t, report.Total.Min, report.Total.P50)
+	require.LessOrEqual(t, report.Total.P50, report.Total.P99)
+	require.LessOrEqual(t, report.Total.P99, report.Total.Max)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.Print(buf))
+	require.Contains(t, buf.String(), "Load results: 45 requests in")
+	require.Contains(t, buf.String(), "First error of test 'always fails'")
+}
+
+func Test_RunLoad_DurationAndRate(t *testing.T) {
+	report, err := newLoadRunner(t).RunLoad(&LoadOpts{
+		Concurrency: 2,
+		Rate:        100,
+		Duration:    200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, report.Duration, 200*time.Millisecond)
+	require.Greater(t, report.Total.Requests, 0)
+	// rate limit allows about 20 requests, leave a gap for slow environments
+	require.LessOrEqual(t, report.Total.Requests, 25)
+}
+
+func Test_RunLoad_Errors(t *testing.T) {
+	_, err := newLoadRunner(t).RunLoad(&LoadOpts{})
+	require.EqualError(t, err, "load mode requires duration or number of iterations")
+
+	r := newLoadRunner(t)
+	r.config.Tags = "unknown"
+	_, err = r.RunLoad(&LoadOpts{Iterations: 1})
+	require.EqualError(t, err, "no tests for load mode: all tests were filtered out or use fixtures, database checks or mocks")
+}
+
+func Test_RunLoad_Steps(t *testing.T) {
+	server := &loadServer{tokens: map[string]bool{}}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	content := `
+- name: login and get profile
+  steps:
+    - method: POST
+      path: /login
+      response:
+        200: '{"token": "$matchRegexp(^token[0-9]+$)"}'
+      variables_to_set:
+        200:
+          stepToken: token
+    - method: GET
+      path: /profile
+      headers:
+        Authorization: "{{ $stepToken }}"
+      response:
+        200: '{"status": "ok"}'
+
+- name: steps with mocks are excluded
+  steps:
+    - method: GET
+      path: /profile
+      mocks:
+        backend:
+          strategy: nop
+      response:
+        200: '{"status": "ok"}'
+`
+	r := New(yaml_file.NewInMemoryLoader(map[string]string{"load.yaml": content}, nil), &RunnerOpts{Host: srv.URL})
+	report, err := r.RunLoad(&LoadOpts{Concurrency: 2, Iterations: 3})
+	require.NoError(t, err)
+
+	require.Len(t, report.Tests, 1)
+	require.Equal(t, "login and get profile", report.Tests[0].Name)
+	require.Equal(t, 6, report.Tests[0].Requests)
+	require.Equal(t, 0, report.Tests[0].Errors, "%v", report.Tests[0].FirstError)
+	require.Equal(t, 6, server.counter)
+}
+
+func Test_percentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	require.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	require.Equal(t, 99*time.Millisecond, percentile(sorted, 99))
+	require.Equal(t, 100*time.Millisecond, percentile(sorted, 100))
+	require.Equal(t, 5*time.Millisecond, percentile(sorted[:5], 99))
+	require.Equal(t, 1*time.Millisecond, percentile(sorted[:1], 50))
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lansfy/gonkex/messagebus"
+	"github.com/lansfy/gonkex/models"
+)
+
+// prepareMessages starts capturing of topics listed in messageChecks section
+// and publishes messages of publishMessages section.
+func (r *Runner) prepareMessages(v models.TestInterface) error {
+	if len(v.PublishMessages()) == 0 && len(v.GetMessageChecks()) == 0 {
+		return nil
+	}
+	if r.config.MessageBus == nil {
+		return errors.New("sections 'publishMessages' and 'messageChecks' require message bus (see RunnerOpts.MessageBus)")
+	}
+
+	topics := []string{}
+	seen := map[string]bool{}
+	for _, check := range v.GetMessageChecks() {
+		if !seen[check.Topic()] {
+			seen[check.Topic()] = true
+			topics = append(topics, check.Topic())
+		}
+	}
+	// capture starts before publishing, so messages produced in reaction to published ones are captured too
+	if err := r.config.MessageBus.StartCapture(topics); err != nil {
+		return fmt.Errorf("start capture of topics %v: %w", topics, err)
+	}
+
+	for _, item := range v.PublishMessages() {
+		messages := []messagebus.Message{}
+		for _, msg := range item.Messages() {
+			messages = append(messages, messagebus.Message{
+				Key:     msg.Key(),
+				Value:   msg.Value(),
+				Headers: msg.Headers(),
+			})
+		}
+		if err := r.config.MessageBus.Publish(item.Topic(), messages); err != nil {
+			return fmt.Errorf("publish messages to topic '%s': %w", item.Topic(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lansfy/gonkex/messagebus"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newOrdersServer creates service, which publishes events of orders to the message bus.
+func newOrdersServer(bus *messagebus.MemoryBus) *httptest.Server {
+	publishEvent := func(id int, status string) {
+		_ = bus.Publish("order-events", []messagebus.Message{{
+			Key:     fmt.Sprint(id),
+			Value:   fmt.Sprintf(`{"id": %d, "status": %q}`, id, status),
+			Headers: map[string]string{"type": status},
+		}})
+	}
+
+	bus.Subscribe("payments", func(msg messagebus.Message) {
+		var payment struct {
+			OrderID int `json:"order_id"`
+		}
+		if err := json.Unmarshal([]byte(msg.Value), &payment); err == nil {
+			publishEvent(payment.OrderID, "paid")
+		}
+	})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			publishEvent(1, "created")
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+}
+
+func Test_Messages(t *testing.T) {
+	bus := messagebus.NewMemoryBus()
+	srv := newOrdersServer(bus)
+	defer srv.Close()
+
+	content := `
+- name: message produced by request
+  method: POST
+  path: /orders
+  response:
+    201: ''
+  messageChecks:
+    - topic: order-events
+      messages:
+        - key: "1"
+          value: '{"id": 1, "status": "$matchRegexp(^cr)"}'
+          headers:
+            type: created
+
+- name: messages produced in reaction to published messages
+  method: GET
+  path: /
+  publishMessages:
+    - topic: payments
+      messages:
+        - value: '{"order_id": 2}'
+        - value: '{"order_id": 3}'
+  messageChecks:
+    - topic: order-events
+      messages:
+        - value: '{"id": 3, "status": "paid"}'
+        - value: '{"id": 2}'
+      comparisonParams:
+        ignoreArraysOrdering: true
+
+- name: wrong message
+  method: POST
+  path: /orders
+  messageChecks:
+    - topic: order-events
+      messages:
+        - key: "2"
+          value: '{"id": 1, "status": "created"}'
+
+- name: missing message
+  method: POST
+  path: /orders
+  messageChecks:
+    - topic: order-events
+      timeout: 50ms
+      messages:
+        - value: '{"id": 1}'
+        - value: '{"id": 2}'
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{Host: srv.URL, MessageBus: bus}, content)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"wrong message": "message check for '$.messageChecks[0].messages': path '$[0].key': values do not match:\n" +
+			"     expected: 2\n       actual: 1",
+		"missing message": "path '$.messageChecks[0]': quantity of 'messages in topic order-events' does not match:\n" +
+			"     expected: 2\n       actual: 1\n\n" +
+			"   diff (--- expected vs +++ actual):\n [\n- '{\"value\":{\"id\":1}}',\n- '{\"value\":{\"id\":2}}',\n" +
+			"+ '{\"value\":{\"id\":1,\"status\":\"created\"}}',\n ]\n",
+	}, errs)
+}
+
+func Test_Messages_WithoutBus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	content := `
+- name: message check without bus
+  method: GET
+  path: /
+  messageChecks:
+    - topic: events
+      messages: []
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{Host: srv.URL}, content)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"message check without bus": "sections 'publishMessages' and 'messageChecks' require message bus (see RunnerOpts.MessageBus)",
+	}, errs)
+}
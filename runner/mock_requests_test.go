@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MockRequests(t *testing.T) {
+	m := mocks.NewNop("payments")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	// service under test, which sends payment to the mock for every order
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		url := fmt.Sprintf("http://%s/payments?currency=EUR", m.Service("payments").ServerAddr())
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", r.Header.Get("X-Request-Id"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = resp.Body.Close()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	content := `
+- name: mock requests match
+  method: POST
+  path: /orders
+  headers:
+    X-Request-Id: req-15
+  request: '{"order_id": 15, "amount": 100}'
+  mocks:
+    payments:
+      strategy: constant
+      body: '{}'
+  mockRequests:
+    payments:
+      count: 1
+      requests:
+        - method: POST
+          path: /payments
+          query: currency=EUR
+          headers:
+            x-request-id: $matchRegexp(^req-[0-9]+$)
+          body: '{"order_id": 15}'
+  response:
+    200: ''
+  variables_to_set:
+    200:
+      paidOrder: mock:payments.0.body.order_id
+
+- name: variable from mock request
+  method: POST
+  path: /orders
+  request: '{"order_id": {{ $paidOrder }}}'
+  mocks:
+    payments:
+      strategy: constant
+      body: '{}'
+  mockRequests:
+    payments:
+      requests:
+        - body: '{"order_id": 15}'
+  response:
+    200: ''
+
+- name: unexpected number of requests
+  method: POST
+  path: /orders
+  request: '{"order_id": 16}'
+  mocks:
+    payments:
+      strategy: constant
+      body: '{}'
+  mockRequests:
+    payments:
+      count: 2
+  response:
+    200: ''
+
+- name: unexpected request
+  method: POST
+  path: /orders
+  request: '{"order_id": 17}'
+  mocks:
+    payments:
+      strategy: constant
+      body: '{}'
+  mockRequests:
+    payments:
+      requests:
+        - body: '{"order_id": 16}'
+  response:
+    200: ''
+
+- name: unknown mock
+  method: POST
+  path: /orders
+  request: '{}'
+  mocks:
+    payments:
+      strategy: constant
+      body: '{}'
+  mockRequests:
+    billing:
+      count: 0
+  response:
+    200: ''
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{
+		Host:        srv.URL,
+		Mocks:       m,
+		MocksLoader: mocks.NewYamlLoader(nil),
+	}, content)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"unexpected number of requests": "path '$.mockRequests.payments.count': quantity of 'requests to mock payments' does not match:\n" +
+			"     expected: 2\n       actual: 1",
+		"unexpected request": "mock requests check for '$.mockRequests.payments.requests': path '$[0].body.order_id': values do not match:\n" +
+			"     expected: 16\n       actual: 17",
+		"unknown mock": "path '$.mockRequests.billing': unknown mock name 'billing'",
+	}, errs)
+}
+
+func Test_MockRequests_Steps(t *testing.T) {
+	m := mocks.NewNop("backend")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	content := `
+- name: requests of all steps are kept
+  mocks:
+    backend:
+      strategy: constant
+      body: '{"source": "test"}'
+  steps:
+    - method: POST
+      path: /first
+      request: '{"step": 1}'
+      response:
+        200: '{"source": "test"}'
+    - method: POST
+      path: /second
+      request: '{"step": 2}'
+      mocks:
+        backend:
+          strategy: constant
+          body: '{"source": "step"}'
+      response:
+        200: '{"source": "step"}'
+      variables_to_set:
+        200:
+          firstPath: mock:backend.0.path
+    - method: POST
+      path: /third
+      request: '{"first": "{{ $firstPath }}"}'
+      response:
+        200: '{"source": "step"}'
+  mockRequests:
+    backend:
+      count: 3
+      requests:
+        - path: /first
+          body: '{"step": 1}'
+        - path: /second
+          body: '{"step": 2}'
+        - path: /third
+          body: '{"first": "/first"}'
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{
+		Host:        "http://" + m.Service("backend").ServerAddr(),
+		Mocks:       m,
+		MocksLoader: mocks.NewYamlLoader(nil),
+	}, content)
+	require.NoError(t, err)
+	require.Empty(t, errs)
+}
@@ -0,0 +1,203 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/output"
+	"github.com/lansfy/gonkex/variables"
+)
+
+var _ output.ExtendedOutputInterface = (*bufferedOutput)(nil)
+
+// bufferedOutput remembers all calls to output and replays them later,
+// so results of concurrently executed files are reported in the original order.
+type bufferedOutput struct {
+	calls []func(o output.OutputInterface) error
+}
+
+func (b *bufferedOutput) BeforeTest(v models.TestInterface) error {
+	b.calls = append(b.calls, func(o output.OutputInterface) error {
+		if exo, ok := o.(output.ExtendedOutputInterface); ok {
+			return exo.BeforeTest(v)
+		}
+		return nil
+	})
+	return nil
+}
+
+func (b *bufferedOutput) Process(v models.TestInterface, result *models.Result) error {
+	b.calls = append(b.calls, func(o output.OutputInterface) error {
+		return o.Process(v, result)
+	})
+	return nil
+}
+
+func (b *bufferedOutput) flush(outputs []output.OutputInterface) error {
+	for _, call := range b.calls {
+		for _, o := range outputs {
+			if err := call(o); err != nil {
+				return err
+			}
+		}
+	}
+	b.calls = nil
+	return nil
+}
+
+type testFile struct {
+	tests  []models.TestInterface
+	serial bool
+
+	buffer *bufferedOutput
+	errs   []error
+	err    error
+	done   chan struct{}
+}
+
+// splitByFiles groups tests by the file they were loaded from.
+// Files which use shared resources (see usesSharedResources) are marked as serial.
+func splitByFiles(tests []models.TestInterface) []*testFile {
+	var files []*testFile
+	for _, t := range tests {
+		if t.FirstTestInFile() || len(files) == 0 {
+			files = append(files, &testFile{
+				buffer: &bufferedOutput{},
+				done:   make(chan struct{}),
+			})
+		}
+		f := files[len(files)-1]
+		f.tests = append(f.tests, t)
+		f.serial = f.serial || usesSharedResources(t)
+	}
+	return files
+}
+
+// usesSharedResources returns true if test works with database, message bus or mocks,
+// so it can't be executed concurrently with other tests.
+func usesSharedResources(t models.TestInterface) bool {
+	if hook := t.BeforeAll(); hook != nil && len(hook.Fixtures()) != 0 {
+		return true
+	}
+	if len(beforeAllMocks(t)) != 0 {
+		return true
+	}
+	if hook := t.AfterAll(); hook != nil && len(hook.Fixtures()) != 0 {
+		return true
+	}
+	if len(t.PublishMessages()) != 0 || len(t.GetMessageChecks()) != 0 {
+		return true
+	}
+	if len(t.GetMockRequests()) != 0 {
+		return true
+	}
+	for _, step := range t.GetSteps() {
+		if usesSharedResources(step) {
+			return true
+		}
+	}
+	return len(t.Fixtures()) != 0 || len(t.GetDatabaseChecks()) != 0 || len(t.ServiceMocks()) != 0
+}
+
+// fork creates a runner which executes tests of a single file in parallel mode.
+func (r *Runner) fork(f *testFile) *Runner {
+	w := &Runner{
+		loader:   r.loader,
+		checkers: r.checkers,
+		config:   r.config,
+		isolated: !f.serial,
+		clients:  r.clients,
+	}
+	w.config.Variables = variables.NewScope(r.config.Variables)
+	if len(r.output) != 0 {
+		w.output = []output.OutputInterface{f.buffer}
+	}
+	return w
+}
+
+// runParallel executes test files concurrently using r.config.Parallel workers.
+// Files which use shared resources are executed exclusively, all other files share the runtime
+// and don't touch mocks (their calls can't be attributed to one of the concurrent tests).
+// Output of every file is reported in the same order as in sequential mode.
+func (r *Runner) runParallel(tests []models.TestInterface, hasFocused bool) ([]error, error) {
+	files := splitByFiles(tests)
+	r.resetMocks()
+
+	var stopMutex sync.Mutex
+	var stop bool
+	stopped := func() bool {
+		stopMutex.Lock()
+		defer stopMutex.Unlock()
+		return stop
+	}
+
+	queue := make(chan *testFile)
+	var exclusive sync.RWMutex
+	var wg sync.WaitGroup
+	for i := 0; i < r.config.Parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range queue {
+				if f.serial {
+					exclusive.Lock()
+				} else {
+					exclusive.RLock()
+				}
+
+				f.errs, f.err = r.fork(f).runSequence(f.tests, hasFocused, stopped)
+				if f.err != nil {
+					stopMutex.Lock()
+					stop = true
+					stopMutex.Unlock()
+				}
+
+				if f.serial {
+					// concurrent files must not get definitions of the mocks of this file
+					r.resetMocks()
+					exclusive.Unlock()
+				} else {
+					exclusive.RUnlock()
+				}
+				close(f.done)
+			}
+		}()
+	}
+
+	go func() {
+		for _, f := range files {
+			if stopped() {
+				close(f.done)
+				continue
+			}
+			queue <- f
+		}
+		close(queue)
+	}()
+
+	var errs []error
+	var fatal error
+	for _, f := range files {
+		<-f.done
+		if fatal != nil {
+			continue
+		}
+		if err := f.buffer.flush(r.output); err != nil {
+			fatal = err
+			continue
+		}
+		errs = append(errs, f.errs...)
+		fatal = f.err
+	}
+	wg.Wait()
+
+	return errs, fatal
+}
+
+// resetMocks restores default definitions of mocks, which are used by files executed concurrently.
+func (r *Runner) resetMocks() {
+	if r.config.Mocks != nil {
+		r.config.Mocks.ResetRunningContext()
+		r.config.Mocks.ResetDefinitions()
+	}
+}
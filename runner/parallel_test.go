@@ -0,0 +1,180 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lansfy/gonkex/mocks"
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/testloader/yaml_file"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitByFiles(t *testing.T) {
+	loader := yaml_file.NewInMemoryLoader(map[string]string{
+		"file1.yaml": `
+- name: test 11
+  path: /
+- name: test 12
+  path: /
+`,
+		"file2.yaml": `
+- name: test 21
+  path: /
+  fixtures: [some_fixture]
+`,
+		"file3.yaml": `
+- name: test 31
+  path: /
+  mocks:
+    service:
+      strategy: nop
+`,
+	}, nil)
+
+	tests, err := loader.Load()
+	require.NoError(t, err)
+
+	files := splitByFiles(tests)
+	require.Len(t, files, 3)
+	require.Len(t, files[0].tests, 2)
+	require.False(t, files[0].serial)
+	require.Len(t, files[1].tests, 1)
+	require.True(t, files[1].serial)
+	require.Len(t, files[2].tests, 1)
+	require.True(t, files[2].serial)
+}
+
+func Test_parallel_mocks(t *testing.T) {
+	m := mocks.NewNop("backend")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	files := map[string]string{
+		"file1.yaml": `
+- name: unexpected call
+  method: GET
+  path: /unexpected
+  response:
+    200: ''
+`,
+		"file2.yaml": `
+- name: variable from mock
+  method: GET
+  path: /expected
+  mocks:
+    backend:
+      strategy: constant
+      body: ok
+  response:
+    200: ok
+  variables_to_set:
+    200:
+      mockPath: mock:backend.0.path
+- name: use variable
+  method: GET
+  path: /expected
+  mocks:
+    backend:
+      strategy: constant
+      body: ok
+  response:
+    200: ok
+  mockRequests:
+    backend:
+      requests:
+        - path: "{{ $mockPath }}"
+`,
+	}
+
+	for _, parallel := range []int{0, 2} {
+		t.Run(fmt.Sprintf("parallel %d", parallel), func(t *testing.T) {
+			var mutex sync.Mutex
+			errs := map[string]string{}
+			r := New(yaml_file.NewInMemoryLoader(files, nil), &RunnerOpts{
+				Host:         "http://" + m.Service("backend").ServerAddr(),
+				Mocks:        m,
+				MocksLoader:  mocks.NewYamlLoader(nil),
+				Parallel:     parallel,
+				OnFailPolicy: PolicyContinue,
+				TestHandler: func(test models.TestInterface, executor TestExecutor) (bool, error) {
+					result, err := executor(test)
+					mutex.Lock()
+					defer mutex.Unlock()
+					switch {
+					case err != nil:
+						errs[test.GetName()] = err.Error()
+					case !result.Passed():
+						errs[test.GetName()] = result.Errors[0].Error()
+					}
+					return false, nil
+				},
+			})
+			require.NoError(t, r.Run())
+			if parallel == 0 {
+				require.Len(t, errs, 1)
+				require.Contains(t, errs["unexpected call"], "mock 'backend'")
+				return
+			}
+			// file without mocks is executed concurrently, so calls to mocks aren't checked for it
+			require.Empty(t, errs)
+		})
+	}
+}
+
+type orderOutput struct {
+	names []string
+}
+
+func (o *orderOutput) Process(v models.TestInterface, _ *models.Result) error {
+	o.names = append(o.names, v.GetName())
+	return nil
+}
+
+func Test_parallel_variables_scope(t *testing.T) {
+	var mutex sync.Mutex
+	paths := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		paths[r.URL.Path] = true
+		mutex.Unlock()
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	files := map[string]string{}
+	expectedNames := []string{}
+	for i := 0; i < 10; i++ {
+		files[fmt.Sprintf("file%02d.yaml", i)] = fmt.Sprintf(`
+- name: set %[1]d
+  method: GET
+  path: /set/%[1]d
+  response:
+    200: /set/%[1]d
+  variables_to_set:
+    200: value
+- name: use %[1]d
+  method: GET
+  path: "/use{{ $value }}"
+  response:
+    200: /use/set/%[1]d
+`, i)
+		expectedNames = append(expectedNames, fmt.Sprintf("set %d", i), fmt.Sprintf("use %d", i))
+	}
+
+	o := &orderOutput{}
+	r := New(yaml_file.NewInMemoryLoader(files, nil), &RunnerOpts{
+		Host:     srv.URL,
+		Parallel: 4,
+	})
+	r.AddOutput(o)
+
+	err := r.Run()
+	require.NoError(t, err)
+	require.Equal(t, expectedNames, o.names)
+	require.Len(t, paths, 20)
+}
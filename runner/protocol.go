@@ -0,0 +1,24 @@
+//go:build go1.24
+
+package runner
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func configureProtocol(transport *http.Transport, protocol string) error {
+	protocols := &http.Protocols{}
+	switch protocol {
+	case "", ProtocolHTTP1:
+		return nil
+	case ProtocolHTTP2:
+		protocols.SetHTTP2(true)
+	case ProtocolH2C:
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		return fmt.Errorf("unknown protocol '%s' (allowed only %s, %s, %s)", protocol, ProtocolHTTP1, ProtocolHTTP2, ProtocolH2C)
+	}
+	transport.Protocols = protocols
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build !go1.24
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+func configureProtocol(transport *http.Transport, protocol string) error {
+	switch protocol {
+	case "", ProtocolHTTP1:
+		return nil
+	case ProtocolHTTP2:
+		// without http.Protocols HTTP/2 can only be negotiated, HTTP/1.1 is used if server doesn't support it
+		transport.ForceAttemptHTTP2 = true
+		return nil
+	case ProtocolH2C:
+		return errors.New("protocol h2c requires Go 1.24 or newer")
+	default:
+		return fmt.Errorf("unknown protocol '%s' (allowed only %s, %s, %s)", protocol, ProtocolHTTP1, ProtocolHTTP2, ProtocolH2C)
+	}
+}
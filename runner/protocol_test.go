@@ -0,0 +1,38 @@
+//go:build go1.24
+
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_H2C(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(protoHandler))
+	srv.Config.Protocols = &http.Protocols{}
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	errs, err := runClientTests(t, &RunnerOpts{Host: srv.URL}, `
+- name: default protocol
+  method: GET
+  path: /
+  response:
+    200: '{"client": "none", "proto": "HTTP/1.1"}'
+
+- name: h2c protocol
+  method: GET
+  path: /
+  client:
+    protocol: h2c
+  response:
+    200: '{"client": "none", "proto": "HTTP/2.0"}'
+`)
+	require.NoError(t, err)
+	require.Empty(t, errs)
+}
@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -13,13 +15,20 @@ import (
 	"github.com/lansfy/gonkex/checker/response_body"
 	"github.com/lansfy/gonkex/checker/response_db"
 	"github.com/lansfy/gonkex/checker/response_header"
+	"github.com/lansfy/gonkex/checker/response_messages"
+	"github.com/lansfy/gonkex/checker/response_mock_requests"
+	"github.com/lansfy/gonkex/checker/response_stream"
+	"github.com/lansfy/gonkex/checker/response_time"
+	"github.com/lansfy/gonkex/checker/response_websocket"
 	"github.com/lansfy/gonkex/cmd_runner"
 	"github.com/lansfy/gonkex/colorize"
 	"github.com/lansfy/gonkex/endpoint"
+	"github.com/lansfy/gonkex/messagebus"
 	"github.com/lansfy/gonkex/mocks"
 	"github.com/lansfy/gonkex/models"
 	"github.com/lansfy/gonkex/output"
 	"github.com/lansfy/gonkex/output/allure"
+	"github.com/lansfy/gonkex/output/junit"
 	"github.com/lansfy/gonkex/storage"
 	"github.com/lansfy/gonkex/testloader"
 	"github.com/lansfy/gonkex/variables"
@@ -54,6 +63,10 @@ type RunnerOpts struct {
 	// DB provides the interface for test storage.
 	DB storage.StorageInterface
 
+	// MessageBus provides the interface for message broker, which is used by
+	// publishMessages and messageChecks sections of tests.
+	MessageBus messagebus.MessageBusInterface
+
 	// Mocks contains mock implementations for external dependencies.
 	Mocks *mocks.Mocks
 
@@ -65,9 +78,16 @@ type RunnerOpts struct {
 	Variables variables.Variables
 
 	// CustomClient is an HTTP client implementation for making test requests.
-	// If nil, a default HTTP client will be used.
+	// If nil, a default HTTP client configured with Client options will be used.
 	CustomClient HTTPClient
 
+	// Client configures TLS and HTTP protocol of the default HTTP client.
+	// Tests can override these options in the client section.
+	Client ClientOpts
+
+	// GRPCClient performs calls of gRPC tests (tests with grpc section).
+	GRPCClient GRPCClient
+
 	// HTTPProxyURL specifies the proxy server URL for HTTP requests.
 	// When set, all HTTP traffic will be routed through this proxy.
 	HTTPProxyURL *url.URL
@@ -84,6 +104,23 @@ type RunnerOpts struct {
 	// OnFailPolicy determines the behavior when a test step fails.
 	// Use PolicySkipFile if not specified.
 	OnFailPolicy OnFailPolicy
+
+	// Parallel sets the number of test files executed concurrently.
+	// Tests inside one file are always executed in order. Each file gets its own variables scope,
+	// and files which use fixtures, database checks, message bus or mocks are executed exclusively.
+	// Values less than 2 mean sequential execution.
+	Parallel int
+
+	// Tags is an expression which selects tests by their tags, e.g. "smoke && !slow".
+	// Supported operators are "!", "&&", "||" and parentheses. Tests which don't match
+	// the expression are marked as skipped. If "gonkex-tags" flag is also provided,
+	// tests must match both expressions.
+	Tags string
+
+	// Recorder enables the record mode: responses of tests which need recording
+	// (e.g. tests without expected response) are written back to the test source
+	// and used as expectations of these tests.
+	Recorder testloader.RecorderInterface
 }
 
 type TestExecutor func(models.TestInterface) (*models.Result, error)
@@ -94,20 +131,24 @@ type Runner struct {
 	output   []output.OutputInterface
 	checkers checkersList
 	config   RunnerOpts
+	// isolated is set for runners which execute tests concurrently with other tests,
+	// such runners must not touch shared mocks state.
+	isolated bool
+	clients  *clientsCache
+	// fileMocks contains definitions of mocks loaded by beforeAll section of the current file.
+	fileMocks map[string]*mocks.Definition
 }
 
 // New creates a new test runner with the given loader and options.
 func New(loader testloader.LoaderInterface, opts *RunnerOpts) *Runner {
 	r := &Runner{
-		loader: loader,
+		loader:  loader,
+		clients: &clientsCache{},
 	}
 	if opts != nil {
 		r.config = *opts
 	}
 
-	if r.config.CustomClient == nil {
-		r.config.CustomClient = newClient(r.config.HTTPProxyURL)
-	}
 	if r.config.TestHandler == nil {
 		r.config.TestHandler = r.defaultTestHandler
 	}
@@ -125,9 +166,16 @@ func New(loader testloader.LoaderInterface, opts *RunnerOpts) *Runner {
 
 	r.AddCheckers(response_body.NewChecker())
 	r.AddCheckers(response_header.NewChecker())
+	r.AddCheckers(response_time.NewChecker())
+	r.AddCheckers(response_websocket.NewChecker())
+	r.AddCheckers(response_stream.NewChecker())
+	r.AddCheckers(response_mock_requests.NewChecker())
 	if r.config.DB != nil {
 		r.AddCheckers(response_db.NewChecker(r.config.DB))
 	}
+	if r.config.MessageBus != nil {
+		r.AddCheckers(response_messages.NewChecker(r.config.MessageBus))
+	}
 	return r
 }
 
@@ -159,16 +207,93 @@ func (r *Runner) Run() error {
 		r.AddOutput(allureOutput)
 	}
 
+	if junitFileFlag != "" {
+		junitOutput, err := junit.NewOutput("Gonkex", junitFileFlag)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = junitOutput.Finalize()
+		}()
+		r.AddOutput(junitOutput)
+	}
+
 	tests, err := r.loader.Load()
 	if err != nil {
 		return err
 	}
 
+	return r.runTests(tests, nil)
+}
+
+// runTests executes the loaded tests. If selected is not nil, only tests for which it returns true
+// (and their dependencies) are executed, all other tests are marked as skipped.
+func (r *Runner) runTests(tests []models.TestInterface, selected func(models.TestInterface) bool) error {
 	hasFocused := checkHasFocused(tests)
+	if err := r.selectTests(tests, hasFocused, selected); err != nil {
+		return err
+	}
+
+	var err error
+	var errs []error
+	if r.config.Parallel > 1 {
+		errs, err = r.runParallel(tests, hasFocused)
+	} else {
+		errs, err = r.runSequence(tests, hasFocused, nil)
+	}
+	if err != nil {
+		return err
+	}
 
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.New("some steps failed")
+	}
+}
+
+// runSequence executes tests one by one, applying the OnFailPolicy to failed tests.
+// Non-critical test errors are accumulated and returned as a slice, while an error
+// which must stop the whole run is returned as a second value.
+// If stopped is not nil, it is checked before every test and the execution ends when it returns true.
+func (r *Runner) runSequence(tests []models.TestInterface, hasFocused bool, stopped func() bool) ([]error, error) {
 	var wasError bool
 	var errs []error
+	var fatal error
+	hooks := &fileHooksState{}
+	dependencies := newDependencyTracker()
+
+	// processError registers the error and returns true if the whole run must be stopped
+	processError := func(t models.TestInterface, critical bool, err error) bool {
+		err = colorize.NewEntityError("test %s error", t.GetName()).WithSubError(err)
+		if hasFocused || critical || r.config.OnFailPolicy == PolicyStop {
+			fatal = err
+			return true
+		}
+		errs = append(errs, err)
+		if r.config.OnFailPolicy == PolicySkipFile {
+			wasError = true
+		}
+		return false
+	}
+
+	processHookErrors := func(t models.TestInterface, hookErrs []error) bool {
+		for _, err := range hookErrs {
+			if processError(t, false, err) {
+				return true
+			}
+		}
+		return false
+	}
+
 	for _, t := range tests {
+		if stopped != nil && stopped() {
+			break
+		}
+
 		if t.FirstTestInFile() {
 			wasError = false
 		}
@@ -184,51 +309,81 @@ func (r *Runner) Run() error {
 			}
 		}
 
-		critical, err := r.config.TestHandler(t, r.executeTest)
-		if err != nil {
-			err = colorize.NewEntityError("test %s error", t.GetName()).WithSubError(err)
-			if hasFocused || critical || r.config.OnFailPolicy == PolicyStop {
-				return err
+		executor := r.executeTest
+		if t.GetStatus() == models.StatusNone {
+			if reason := dependencies.check(t); reason != nil {
+				t.SetStatus(models.StatusSkipped)
+				executor = skipWithReason(executor, reason)
 			}
-			errs = append(errs, err)
-			if r.config.OnFailPolicy == PolicySkipFile {
-				wasError = true
+		}
+
+		if err := hooks.before(r, t); err != nil {
+			if processError(t, false, err) {
+				break
 			}
+			// tests of the file can't be executed without successful setup
+			wasError = true
+			t.SetStatus(models.StatusSkipped)
+		}
+
+		critical, err := r.config.TestHandler(t, executor)
+		dependencies.register(t, err)
+		if err != nil && processError(t, critical, err) {
+			break
+		}
+
+		if t.LastTestInFile() && processHookErrors(t, hooks.after(r)) {
+			break
 		}
 	}
 
-	switch len(errs) {
-	case 0:
-		return nil
-	case 1:
-		return errs[0]
-	default:
-		return errors.New("some steps failed")
+	// afterAll section must be executed even if the run was stopped in the middle of the file
+	errs = append(errs, hooks.after(r)...)
+
+	return errs, fatal
+}
+
+// makeRequest performs the request of the test with HTTP client, gRPC client or over WebSocket connection.
+func (r *Runner) makeRequest(v models.TestInterface) (*models.Result, error) {
+	if v.GetGRPC() != nil {
+		return makeGRPCRequest(r.config.GRPCClient, v)
+	}
+	if v.GetWebSocket() != nil {
+		return r.makeWebSocketRequest(v)
 	}
+
+	client, err := r.httpClient(v)
+	if err != nil {
+		return nil, err
+	}
+	return makeServiceRequest(&r.config, client, v)
 }
 
-func makeServiceRequest(config *RunnerOpts, v models.TestInterface) (*models.Result, error) {
+func makeServiceRequest(config *RunnerOpts, client HTTPClient, v models.TestInterface) (*models.Result, error) {
 	req, reqBody, err := endpoint.NewRequest(config.Host, v)
 	if err != nil {
 		return nil, err
 	}
 
+	stream := v.GetStream()
+	cancel := func() {}
+	if stream != nil {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(req.Context())
+		req = req.WithContext(ctx)
+	}
+	defer cancel()
+
 	var resp *http.Response
+	recorder := newTimingsRecorder()
+	req = recorder.attach(req)
 	prefix := config.HelperPrefix
 	if strings.HasPrefix(req.URL.Path, prefix) {
 		path := req.URL.Path[len(prefix):]
 		resp, err = endpoint.SelectEndpoint(config.HelperEndpoints, prefix, path, req, config.Mocks, v) //nolint:bodyclose // false positive
 	} else {
-		resp, err = config.CustomClient.Do(req) //nolint:bodyclose // false positive
-	}
-	if err != nil {
-		return nil, err
+		resp, err = client.Do(req) //nolint:bodyclose // false positive
 	}
-
-	body, err := io.ReadAll(resp.Body)
-
-	_ = resp.Body.Close()
-
 	if err != nil {
 		return nil, err
 	}
@@ -237,7 +392,6 @@ func makeServiceRequest(config *RunnerOpts, v models.TestInterface) (*models.Res
 		Path:                req.URL.Path,
 		Query:               req.URL.RawQuery,
 		RequestBody:         reqBody,
-		ResponseBody:        string(body),
 		ResponseContentType: resp.Header.Get("Content-Type"),
 		ResponseStatusCode:  resp.StatusCode,
 		ResponseStatus:      resp.Status,
@@ -245,6 +399,22 @@ func makeServiceRequest(config *RunnerOpts, v models.TestInterface) (*models.Res
 		Test:                v,
 	}
 
+	if stream != nil {
+		err = readStreamResponse(resp, stream, cancel, result)
+	} else {
+		var body []byte
+		body, err = io.ReadAll(resp.Body)
+		result.ResponseBody = string(body)
+	}
+
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.Timings = recorder.finish()
+
 	// support for Trailer headers: https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Trailer
 	for name, value := range resp.Trailer {
 		result.ResponseHeaders[name] = value
@@ -307,11 +477,10 @@ func (r *Runner) executeTest(v models.TestInterface) (*models.Result, error) {
 	}
 
 	// reset mocks
-	if r.config.Mocks != nil {
+	if r.config.Mocks != nil && !r.isolated {
 		if !v.ServiceMocksParams().SkipMocksResetBeforeTest() {
 			// prevent deriving the definition from previous test
-			r.config.Mocks.ResetRunningContext()
-			r.config.Mocks.ResetDefinitions()
+			r.resetTestMocks()
 		}
 
 		// load mocks
@@ -323,6 +492,11 @@ func (r *Runner) executeTest(v models.TestInterface) (*models.Result, error) {
 		}
 	}
 
+	err = r.prepareMessages(v)
+	if err != nil {
+		return nil, err
+	}
+
 	// launch script in cmd interface
 	if v.BeforeScript().CmdLine() != "" {
 		_, err = cmd_runner.ExecuteScript(v.BeforeScript())
@@ -340,7 +514,8 @@ func (r *Runner) executeTest(v models.TestInterface) (*models.Result, error) {
 
 	retryCheckers := checkersList{}
 	if retryCount != 0 {
-		retryCheckers.AddCheckers(response_body.NewChecker(), response_header.NewChecker())
+		retryCheckers.AddCheckers(response_body.NewChecker(), response_header.NewChecker(),
+			response_websocket.NewChecker(), response_stream.NewChecker())
 	}
 
 	var errs []error
@@ -351,14 +526,23 @@ func (r *Runner) executeTest(v models.TestInterface) (*models.Result, error) {
 			time.Sleep(retryPolicy.Delay())
 		}
 
-		result, err = makeServiceRequest(&r.config, v)
-		if err != nil {
-			return nil, err
-		}
+		if len(v.GetSteps()) != 0 {
+			// the whole scenario is retried, so errors of the steps are the result of the attempt
+			result, err = r.runSteps(v)
+			if err != nil {
+				return nil, err
+			}
+			errs = result.Errors
+		} else {
+			result, err = r.makeRequest(v)
+			if err != nil {
+				return nil, err
+			}
 
-		errs, err = retryCheckers.Check(v, result)
-		if err != nil {
-			return nil, err
+			errs, err = retryCheckers.Check(v, result)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		if len(errs) != 0 {
@@ -391,10 +575,10 @@ func (r *Runner) executeTest(v models.TestInterface) (*models.Result, error) {
 		}
 	}
 
-	if r.config.Mocks != nil {
-		errs := r.config.Mocks.EndRunningContext(v.ServiceMocksParams().SkipMocksResetAfterTest())
-		result.Errors = append(result.Errors, errs...)
+	if r.config.Mocks != nil && !r.isolated {
+		result.Errors = append(result.Errors, r.endTestMocks(v)...)
 	}
+	r.collectMockRequests(result)
 
 	skipCheckers := false
 	changed, errs := r.setVariablesFromResponse(v, result)
@@ -410,6 +594,14 @@ func (r *Runner) executeTest(v models.TestInterface) (*models.Result, error) {
 		v.ApplyVariables(r.config.Variables.Substitute)
 	}
 
+	if r.config.Recorder != nil && r.config.Recorder.NeedsRecording(v) {
+		v, err = r.config.Recorder.Record(v, result)
+		if err != nil {
+			return nil, err
+		}
+		result.Test = v
+	}
+
 	if !skipCheckers {
 		errs, err = r.checkers.Check(v, result)
 		if err != nil {
@@ -429,6 +621,25 @@ func (r *Runner) executeTest(v models.TestInterface) (*models.Result, error) {
 	return result, nil
 }
 
+// collectMockRequests stores requests received by mocks to the result, so they can be used
+// by mockRequests section and variables_to_set.
+func (r *Runner) collectMockRequests(result *models.Result) {
+	if r.config.Mocks == nil || r.isolated {
+		return
+	}
+
+	result.MockRequests = map[string]string{}
+	for _, name := range r.config.Mocks.GetNames() {
+		requests, _ := r.config.Mocks.Requests(name)
+		items := make([]interface{}, len(requests))
+		for i := range requests {
+			items[i] = requests[i].ToMap()
+		}
+		data, _ := json.Marshal(items)
+		result.MockRequests[name] = string(data)
+	}
+}
+
 func (r *Runner) setVariablesFromResponse(t models.TestInterface, result *models.Result) (bool, []error) {
 	varTemplates, ok := t.GetVariablesToSet(result.ResponseStatusCode)
 	if !ok || len(varTemplates) == 0 {
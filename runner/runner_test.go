@@ -8,14 +8,17 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/lansfy/gonkex/endpoint"
 	"github.com/lansfy/gonkex/mocks"
 	"github.com/lansfy/gonkex/models"
 	"github.com/lansfy/gonkex/output"
+	"github.com/lansfy/gonkex/testloader"
 	"github.com/lansfy/gonkex/testloader/yaml_file"
 
 	"github.com/stretchr/testify/assert"
@@ -336,29 +339,32 @@ func Test_on_fail_policy_with_test_error(t *testing.T) {
 			wantErr: "some steps failed",
 		},
 	}
-	for _, tt := range testCases {
-		t.Run(policyToStr(tt.policy), func(t *testing.T) {
-			obj := newStatusServer()
-			srv := httptest.NewServer(obj)
-			defer srv.Close()
-
-			yamlLoader := yaml_file.NewLoader("testdata/on-fail-policy/" + tt.postfix)
-			runner := New(
-				yamlLoader,
-				&RunnerOpts{
-					Host:         srv.URL,
-					OnFailPolicy: tt.policy,
-				},
-			)
+	for _, parallel := range []int{0, 3} {
+		for _, tt := range testCases {
+			t.Run(fmt.Sprintf("%s_parallel%d", policyToStr(tt.policy), parallel), func(t *testing.T) {
+				obj := newStatusServer()
+				srv := httptest.NewServer(obj)
+				defer srv.Close()
+
+				yamlLoader := yaml_file.NewLoader("testdata/on-fail-policy/" + tt.postfix)
+				runner := New(
+					yamlLoader,
+					&RunnerOpts{
+						Host:         srv.URL,
+						OnFailPolicy: tt.policy,
+						Parallel:     parallel,
+					},
+				)
 
-			runner.AddOutput(obj)
+				runner.AddOutput(obj)
 
-			err := runner.Run()
-			require.Error(t, err)
-			require.Equal(t, tt.wantErr, err.Error())
-			require.Equal(t, tt.total, obj.totalTests, "total number of test is different")
-			require.Equal(t, tt.result, obj.output, "result of tests is different")
-		})
+				err := runner.Run()
+				require.Error(t, err)
+				require.Equal(t, tt.wantErr, err.Error())
+				require.Equal(t, tt.total, obj.totalTests, "total number of test is different")
+				require.Equal(t, tt.result, obj.output, "result of tests is different")
+			})
+		}
 	}
 }
 
@@ -451,3 +457,276 @@ func Test_Documentation_Examples(t *testing.T) {
 		Mocks:    m,
 	})
 }
+
+type hooksStorage struct {
+	loaded []string
+}
+
+func (s *hooksStorage) GetType() string {
+	return "dummy"
+}
+
+func (s *hooksStorage) LoadFixtures(location string, names []string) error {
+	s.loaded = append(s.loaded, names...)
+	return nil
+}
+
+func (s *hooksStorage) ExecuteQuery(query string) ([]json.RawMessage, error) {
+	return nil, nil
+}
+
+func Test_file_hooks(t *testing.T) {
+	obj := newStatusServer()
+	srv := httptest.NewServer(obj)
+	defer srv.Close()
+
+	db := &hooksStorage{}
+	runner := New(
+		yaml_file.NewLoader("testdata/file-hooks"),
+		&RunnerOpts{
+			Host: srv.URL,
+			DB:   db,
+		},
+	)
+	runner.AddOutput(obj)
+
+	err := runner.Run()
+	require.Error(t, err)
+	require.Equal(t, "test 'test 12' error: failed", err.Error())
+	require.Equal(t, map[string]string{"hooks1.yaml": ".es", "hooks2.yaml": "s"}, obj.output)
+	// afterAll must be executed even if some tests were skipped,
+	// but it is not executed for files without executed tests
+	require.Equal(t, []string{"before1", "after1"}, db.loaded)
+}
+
+func Test_file_hooks_script(t *testing.T) {
+	runner := New(
+		yaml_file.NewInMemoryLoader(map[string]string{"hooks.yaml": `
+- beforeAll:
+    variables:
+      name: missing
+    script:
+      path: ./{{ $name }}-script.sh
+
+- name: test 1
+  method: GET
+  path: /endpoint
+  response:
+    200: '{}'
+`}, nil),
+		&RunnerOpts{Host: "http://localhost"},
+	)
+
+	err := runner.Run()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "test 'test 1' error: section 'beforeAll'")
+	require.Contains(t, err.Error(), "missing-script.sh")
+}
+
+func Test_file_hooks_mocks(t *testing.T) {
+	m := mocks.NewNop("backend")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	files := map[string]string{
+		"hooks.yaml": `
+- beforeAll:
+    variables:
+      first: one
+    mocks:
+      backend:
+        strategy: sequence
+        calls: CALLS
+        sequence:
+          - strategy: constant
+            body: "{{ $first }}"
+          - strategy: constant
+            body: two
+
+- name: first call
+  method: GET
+  path: /
+  response:
+    200: one
+
+- name: own mock
+  method: GET
+  path: /
+  mocks:
+    backend:
+      strategy: constant
+      body: own
+  response:
+    200: own
+
+- name: second call
+  method: GET
+  path: /
+  response:
+    200: two
+`,
+		"other.yaml": `
+- name: default mock
+  method: GET
+  path: /
+  response:
+    200: ''
+`,
+	}
+
+	for _, calls := range []int{2, 3} {
+		t.Run(fmt.Sprintf("calls %d", calls), func(t *testing.T) {
+			errs := map[string]string{}
+			files := map[string]string{
+				"hooks.yaml": strings.Replace(files["hooks.yaml"], "CALLS", fmt.Sprint(calls), 1),
+				"other.yaml": files["other.yaml"],
+			}
+			r := New(yaml_file.NewInMemoryLoader(files, nil), &RunnerOpts{
+				Host:         "http://" + m.Service("backend").ServerAddr(),
+				Mocks:        m,
+				MocksLoader:  mocks.NewYamlLoader(nil),
+				OnFailPolicy: PolicyContinue,
+				TestHandler: func(test models.TestInterface, executor TestExecutor) (bool, error) {
+					result, err := executor(test)
+					switch {
+					case err != nil:
+						errs[test.GetName()] = err.Error()
+					case !result.Passed():
+						errs[test.GetName()] = result.Errors[0].Error()
+					}
+					return false, nil
+				},
+			})
+
+			err := r.Run()
+			// mock loaded by beforeAll isn't available in other files
+			require.Contains(t, errs["default mock"], "mock 'backend'")
+			delete(errs, "default mock")
+			// state of the mock is kept between tests, calls are checked after the last test
+			require.Empty(t, errs)
+			if calls == 2 {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "test 'second call' error: mock 'backend'")
+				require.Contains(t, err.Error(), "number of 'calls' does not match")
+			}
+			// variables of beforeAll are dropped after the file
+			require.Equal(t, "{{ $first }}", r.config.Variables.Substitute("{{ $first }}"))
+		})
+	}
+}
+
+func Test_dependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     string
+		expected string
+		reasons  map[string]string
+	}{
+		{
+			name:     "all tests",
+			expected: ".es.ss",
+			reasons: map[string]string{
+				"get order":    "test was skipped: dependency 'create order' failed",
+				"refund order": "test was skipped",
+				"get refund":   "test was skipped: dependency 'refund order' was skipped",
+			},
+		},
+		{
+			name:     "dependencies are pulled in by tags selection",
+			tags:     "orders",
+			expected: ".essss",
+			reasons: map[string]string{
+				"get order":    "test was skipped: dependency 'create order' failed",
+				"logout":       "test was skipped",
+				"refund order": "test was skipped",
+				"get refund":   "test was skipped",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := newStatusServer()
+			srv := httptest.NewServer(obj)
+			defer srv.Close()
+
+			reasons := map[string]string{}
+			runner := New(
+				yaml_file.NewLoader("testdata/dependencies"),
+				&RunnerOpts{
+					Host:         srv.URL,
+					Tags:         tt.tags,
+					OnFailPolicy: PolicyContinue,
+					TestHandler: func(test models.TestInterface, executor TestExecutor) (bool, error) {
+						result, err := executor(test)
+						if isTestWasSkipped(err) {
+							reasons[test.GetName()] = err.Error()
+							return false, nil
+						}
+						if err != nil {
+							return true, err
+						}
+						if !result.Passed() {
+							return false, errors.New("failed")
+						}
+						return false, nil
+					},
+				},
+			)
+			runner.AddOutput(obj)
+
+			err := runner.Run()
+			require.EqualError(t, err, "test 'create order' error: failed")
+			require.Equal(t, map[string]string{"dependencies.yaml": tt.expected}, obj.output)
+			require.Equal(t, tt.reasons, reasons)
+		})
+	}
+}
+
+func Test_record_mode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(rw, `{"path":%q,"created":%q}`, r.URL.Path, time.Now().Format(time.RFC3339Nano))
+	}))
+	defer srv.Close()
+
+	fileName := filepath.Join(t.TempDir(), "record.yaml")
+	content := `
+- name: recorded test
+  method: GET
+  path: /first
+
+- name: test with marker
+  method: GET
+  path: /second
+  response:
+    200: $record
+`
+	require.NoError(t, os.WriteFile(fileName, []byte(content), 0o644))
+
+	run := func(recorder testloader.RecorderInterface) *statusServer {
+		obj := newStatusServer()
+		runner := New(yaml_file.NewLoader(fileName), &RunnerOpts{
+			Host:     srv.URL,
+			Recorder: recorder,
+		})
+		runner.AddOutput(obj)
+		require.NoError(t, runner.Run())
+		return obj
+	}
+
+	obj := run(yaml_file.NewRecorder(&yaml_file.RecorderOpts{Patterns: true}))
+	require.Equal(t, map[string]string{"record.yaml": ".."}, obj.output)
+
+	recorded, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	require.Contains(t, string(recorded), `"path": "/first"`)
+	require.Contains(t, string(recorded), `"path": "/second"`)
+	require.Contains(t, string(recorded), `"created": "$matchTime(2006-01-02T15:04:05Z07:00)"`)
+	require.NotContains(t, string(recorded), "$record")
+
+	// recorded expectations must pass without record mode
+	obj = run(nil)
+	require.Equal(t, map[string]string{"record.yaml": ".."}, obj.output)
+}
@@ -4,16 +4,19 @@ import (
 	"errors"
 	"net/url"
 	"os"
+	"sync/atomic"
 	"testing"
 	"text/template"
 
 	"github.com/lansfy/gonkex/checker"
 	"github.com/lansfy/gonkex/endpoint"
+	"github.com/lansfy/gonkex/messagebus"
 	"github.com/lansfy/gonkex/mocks"
 	"github.com/lansfy/gonkex/models"
 	"github.com/lansfy/gonkex/output"
 	"github.com/lansfy/gonkex/output/terminal"
 	"github.com/lansfy/gonkex/storage"
+	"github.com/lansfy/gonkex/testloader"
 	"github.com/lansfy/gonkex/testloader/yaml_file"
 	"github.com/lansfy/gonkex/variables"
 )
@@ -36,6 +39,8 @@ type RunWithTestingOpts struct {
 	Mocks *mocks.Mocks
 	// DB is the storage interface used for interacting with the database during tests.
 	DB storage.StorageInterface
+	// MessageBus is the message broker used by publishMessages and messageChecks sections of tests.
+	MessageBus messagebus.MessageBusInterface
 	// MainOutputFunc is the primary output handler for the testing run.
 	MainOutputFunc output.OutputInterface
 	// Outputs is a collection of additional output handlers to process test results.
@@ -45,6 +50,10 @@ type RunWithTestingOpts struct {
 
 	// CustomClient is a custom HTTP client used for making requests to the server during tests.
 	CustomClient HTTPClient
+	// Client configures TLS and HTTP protocol of the default HTTP client (see RunnerOpts.Client).
+	Client ClientOpts
+	// GRPCClient performs calls of gRPC tests (see RunnerOpts.GRPCClient).
+	GRPCClient GRPCClient
 	// HelperPrefix consists common prefix for all HelperEndpoints.
 	HelperPrefix string
 	// HelperEndpoints is a map of helper endpoints available for facilitating tests.
@@ -53,6 +62,10 @@ type RunWithTestingOpts struct {
 	TemplateFuncs template.FuncMap
 	// OnFailPolicy defining what happens when a some step of test fails.
 	OnFailPolicy OnFailPolicy
+	// Parallel sets the number of test files executed concurrently (see RunnerOpts.Parallel).
+	Parallel int
+	// Tags is an expression which selects tests by their tags (see RunnerOpts.Tags).
+	Tags string
 }
 
 // RunWithTesting is a helper function that wraps the common Run function and provides a simple way
@@ -91,6 +104,30 @@ func RunWithTesting(t *testing.T, serverURL string, opts *RunWithTestingOpts) {
 		allureDirFlag = os.Getenv("GONKEX_ALLURE_DIR")
 	}
 
+	if junitFileFlag == "" {
+		junitFileFlag = os.Getenv("GONKEX_JUNIT_FILE")
+	}
+
+	if tagsFlag == "" {
+		tagsFlag = os.Getenv("GONKEX_TAGS")
+	}
+
+	var recordMode yaml_file.RecordMode
+	if recordFlag || os.Getenv("GONKEX_RECORD") != "" {
+		recordMode |= yaml_file.RecordMissing
+	}
+	if updateFlag || os.Getenv("GONKEX_UPDATE") != "" {
+		recordMode |= yaml_file.RecordFiles
+	}
+
+	var recorder testloader.RecorderInterface
+	if recordMode != 0 {
+		recorder = yaml_file.NewRecorder(&yaml_file.RecorderOpts{
+			Mode:     recordMode,
+			Patterns: recordPatternsFlag || os.Getenv("GONKEX_RECORD_PATTERNS") != "",
+		})
+	}
+
 	handler := &testingHandler{
 		t: t,
 	}
@@ -104,12 +141,19 @@ func RunWithTesting(t *testing.T, serverURL string, opts *RunWithTestingOpts) {
 			}),
 			FixturesDir:     opts.FixturesDir,
 			DB:              opts.DB,
+			MessageBus:      opts.MessageBus,
 			Variables:       opts.Variables,
 			HTTPProxyURL:    proxyURL,
+			CustomClient:    opts.CustomClient,
+			Client:          opts.Client,
+			GRPCClient:      opts.GRPCClient,
 			HelperPrefix:    opts.HelperPrefix,
 			HelperEndpoints: opts.HelperEndpoints,
 			TestHandler:     handler.HandleTest,
 			OnFailPolicy:    opts.OnFailPolicy,
+			Parallel:        opts.Parallel,
+			Tags:            opts.Tags,
+			Recorder:        recorder,
 		},
 	)
 
@@ -120,7 +164,7 @@ func RunWithTesting(t *testing.T, serverURL string, opts *RunWithTestingOpts) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if !handler.executed {
+	if atomic.LoadInt32(&handler.executed) == 0 {
 		t.Skip("no tests to run: none found or all were filtered out")
 	}
 }
@@ -139,18 +183,18 @@ func addOutputs(runner *Runner, opts *RunWithTestingOpts) {
 
 type testingHandler struct {
 	t        *testing.T
-	executed bool
+	executed int32 // accessed atomically, handler can be called from several goroutines
 }
 
 func (h *testingHandler) HandleTest(test models.TestInterface, executor TestExecutor) (bool, error) {
-	h.executed = true
+	atomic.StoreInt32(&h.executed, 1)
 	var critical bool
 	var returnErr error
 	h.t.Run(test.GetName(), func(t *testing.T) {
 		result, err := executor(test)
 		if err != nil {
 			if isTestWasSkipped(err) {
-				t.Skip()
+				t.Skip(err)
 			} else {
 				returnErr = err
 				critical = true
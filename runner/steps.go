@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"github.com/lansfy/gonkex/colorize"
+	"github.com/lansfy/gonkex/models"
+)
+
+// runSteps executes requests of the multi-step test one by one and stops at the first failed step.
+// Errors of the steps are returned in the result, which describes the last executed step.
+func (r *Runner) runSteps(v models.TestInterface) (*models.Result, error) {
+	result := &models.Result{Test: v}
+	for _, step := range v.GetSteps() {
+		// variables can be set by previous steps
+		step = step.Clone()
+		step.ApplyVariables(r.config.Variables.Substitute)
+
+		wrap := func(err error) *colorize.Error {
+			return colorize.NewEntityError("step %s", step.GetName()).WithSubError(err)
+		}
+
+		if r.config.Mocks != nil && !r.isolated && step.ServiceMocks() != nil {
+			errs, err := r.loadStepMocks(step)
+			if err != nil {
+				return nil, wrap(err)
+			}
+			result.Errors = append(result.Errors, errs...)
+		}
+
+		stepResult, err := r.makeRequest(step)
+		if err != nil {
+			return nil, wrap(err)
+		}
+		if err := r.checkStep(step, stepResult); err != nil {
+			return nil, wrap(err)
+		}
+
+		result.Steps = append(result.Steps, stepResult)
+		result.Path = stepResult.Path
+		result.Query = stepResult.Query
+		result.RequestBody = stepResult.RequestBody
+		result.ResponseStatusCode = stepResult.ResponseStatusCode
+		result.ResponseStatus = stepResult.ResponseStatus
+		result.ResponseContentType = stepResult.ResponseContentType
+		result.ResponseHeaders = stepResult.ResponseHeaders
+		result.ResponseBody = stepResult.ResponseBody
+		result.Timings.Total += stepResult.Timings.Total
+
+		if len(stepResult.Errors) != 0 {
+			for _, err := range stepResult.Errors {
+				result.Errors = append(result.Errors, wrap(err))
+			}
+			break
+		}
+	}
+	return result, nil
+}
+
+// checkStep assigns variables from the response of the step and checks the response.
+func (r *Runner) checkStep(step models.TestInterface, result *models.Result) error {
+	r.collectMockRequests(result)
+	changed, errs := r.setVariablesFromResponse(step, result)
+	if len(errs) != 0 {
+		result.Errors = append(result.Errors, errs...)
+		return nil
+	}
+	if changed {
+		step.ApplyVariables(r.config.Variables.Substitute)
+	}
+
+	errs, err := r.checkers.Check(step, result)
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	return nil
+}
+
+// loadStepMocks replaces definitions of the mocks listed in the step. Replaced definitions
+// are verified before replacement, because their calls can't be checked at the end of the test.
+func (r *Runner) loadStepMocks(step models.TestInterface) ([]error, error) {
+	var errs []error
+	for name := range step.ServiceMocks() {
+		if service := r.config.Mocks.Service(name); service != nil {
+			errs = append(errs, service.EndRunningContext(false)...)
+			// requests received by previous steps are kept for mockRequests section and mock: variables
+			service.ResetDefinitionContext()
+		}
+	}
+	return errs, r.config.MocksLoader.LoadRawDefinition(r.config.Mocks, step.ServiceMocks())
+}
@@ -0,0 +1,209 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lansfy/gonkex/mocks"
+
+	"github.com/stretchr/testify/require"
+)
+
+// itemsServer is a simple CRUD service with authorization by token.
+type itemsServer struct {
+	mutex   sync.Mutex
+	items   map[string]string
+	lastID  int
+	flaky   int
+	history []string
+}
+
+func (s *itemsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.history = append(s.history, r.Method+" "+r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("X-Client") != "gonkex" {
+		http.Error(w, `{"error": "unknown client"}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Path == "/login" {
+		_, _ = fmt.Fprint(w, `{"token": "secret"}`)
+		return
+	}
+	if r.URL.Path == "/flaky" {
+		s.flaky++
+		if s.flaky == 1 {
+			http.Error(w, `{"error": "try later"}`, http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"status": "ok"}`)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, `{"error": "unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/items/")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/items":
+		var item struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&item)
+		s.lastID++
+		s.items[fmt.Sprint(s.lastID)] = item.Name
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"id": %d}`, s.lastID)
+	case r.Method == http.MethodGet && s.items[id] != "":
+		_, _ = fmt.Fprintf(w, `{"id": %s, "name": %q}`, id, s.items[id])
+	case r.Method == http.MethodDelete && s.items[id] != "":
+		delete(s.items, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, `{"error": "not found"}`, http.StatusNotFound)
+	}
+}
+
+func Test_Steps(t *testing.T) {
+	server := &itemsServer{items: map[string]string{}}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	content := `
+- name: item lifecycle
+  headers:
+    X-Client: gonkex
+  steps:
+    - name: login
+      method: POST
+      path: /login
+      response:
+        200: '{"token": "$matchRegexp(.+)"}'
+      variables_to_set:
+        200:
+          token: token
+    - name: create
+      method: POST
+      path: /items
+      headers:
+        Authorization: Bearer {{ $token }}
+      request: '{"name": "book"}'
+      response:
+        201: '{"id": "$matchRegexp(^[0-9]+$)"}'
+      variables_to_set:
+        201:
+          itemID: id
+    - method: GET
+      path: /items/{{ $itemID }}
+      headers:
+        Authorization: Bearer {{ $token }}
+      response:
+        200: '{"id": {{ $itemID }}, "name": "book"}'
+    - name: delete
+      method: DELETE
+      path: /items/{{ $itemID }}
+      headers:
+        Authorization: Bearer {{ $token }}
+      response:
+        204: ''
+
+- name: failed step stops scenario
+  headers:
+    X-Client: gonkex
+  steps:
+    - name: fetch deleted item
+      method: GET
+      path: /items/1
+      headers:
+        Authorization: Bearer secret
+      response:
+        200: '{}'
+    - name: never executed
+      method: GET
+      path: /never
+      response:
+        200: '{}'
+
+- name: scenario is retried as a whole
+  headers:
+    X-Client: gonkex
+  retryPolicy:
+    attempts: 1
+  steps:
+    - method: POST
+      path: /login
+      response:
+        200: '{"token": "secret"}'
+    - method: GET
+      path: /flaky
+      response:
+        200: '{"status": "ok"}'
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{Host: srv.URL}, content)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"failed step stops scenario": "step 'fetch deleted item': server responded with unexpected 'status code':\n" +
+			"     expected: 200\n       actual: 404",
+	}, errs)
+	require.Equal(t, []string{
+		"POST /login", "POST /items", "GET /items/1", "DELETE /items/1",
+		"GET /items/1",
+		"POST /login", "GET /flaky", "POST /login", "GET /flaky",
+	}, server.history)
+}
+
+func Test_Steps_Mocks(t *testing.T) {
+	m := mocks.NewNop("backend")
+	require.NoError(t, m.Start())
+	defer m.Shutdown()
+
+	content := `
+- name: mocks of steps
+  mocks:
+    backend:
+      strategy: constant
+      body: '{"source": "test"}'
+  steps:
+    - method: GET
+      path: /
+      response:
+        200: '{"source": "test"}'
+    - method: GET
+      path: /
+      mocks:
+        backend:
+          strategy: constant
+          body: '{"source": "step"}'
+          calls: 2
+      response:
+        200: '{"source": "step"}'
+    - method: GET
+      path: /
+      mocks:
+        backend:
+          strategy: constant
+          body: '{"source": "last step"}'
+      response:
+        200: '{"source": "last step"}'
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{
+		Host:        "http://" + m.Service("backend").ServerAddr(),
+		Mocks:       m,
+		MocksLoader: mocks.NewYamlLoader(nil),
+	}, content)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"mocks of steps": "mock 'backend': path '$': number of 'calls' does not match:\n     expected: 2\n       actual: 1",
+	}, errs)
+}
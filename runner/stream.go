@@ -0,0 +1,163 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lansfy/gonkex/checker/response_stream"
+	"github.com/lansfy/gonkex/models"
+)
+
+const (
+	// defaultStreamTimeout is used if timeout of the stream section is not set.
+	defaultStreamTimeout = 5 * time.Second
+	maxStreamLineSize    = 1024 * 1024
+)
+
+var errStopStream = errors.New("all expected events are received")
+
+// readStreamResponse reads events from the streaming response until all expected events are received.
+// If the order of events is ignored, reading continues until every expected event has matching received event.
+// The body of the result is JSON array with data of received events.
+func readStreamResponse(resp *http.Response, params models.StreamParams, cancel func(),
+	result *models.Result) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		result.ResponseBody = string(body)
+		result.StreamError = fmt.Errorf("events were not read, server responded with status %s", resp.Status)
+		return nil
+	}
+
+	format := params.Format()
+	if format == "" {
+		format = "ndjson"
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if mediaType == "text/event-stream" {
+			format = "sse"
+		}
+	}
+
+	timeout := params.Timeout()
+	if timeout <= 0 {
+		timeout = defaultStreamTimeout
+	}
+	// cancellation of the request context interrupts reading of the body
+	timer := time.AfterFunc(timeout, cancel)
+
+	expected := len(params.Events())
+	matcher := response_stream.NewUnorderedMatcher(params.Events())
+	matched := func() int {
+		if params.IgnoreOrdering() {
+			return matcher.Matched()
+		}
+		return len(result.Stream)
+	}
+	onEvent := func(event models.StreamEvent) error {
+		result.Stream = append(result.Stream, event)
+		if params.IgnoreOrdering() {
+			matcher.Add(event)
+		}
+		if matched() == expected {
+			return errStopStream
+		}
+		return nil
+	}
+
+	var err error
+	if format == "sse" {
+		err = parseSSE(resp.Body, onEvent)
+	} else {
+		err = parseNDJSON(resp.Body, onEvent)
+	}
+	timedOut := !timer.Stop()
+
+	switch {
+	case errors.Is(err, errStopStream):
+	case timedOut:
+		result.StreamError = fmt.Errorf("received %d of %d events within %s", matched(), expected, timeout)
+	case err != nil:
+		result.StreamError = fmt.Errorf("read stream: %w", err)
+	default:
+		result.StreamError = fmt.Errorf("stream was closed after %d of %d events", matched(), expected)
+	}
+
+	received := make([]json.RawMessage, 0, len(result.Stream))
+	for _, event := range result.Stream {
+		received = append(received, toRawJSON(event.Data))
+	}
+	body, err := json.Marshal(received)
+	if err != nil {
+		return err
+	}
+	result.ResponseBody = string(body)
+	result.ResponseContentType = "application/json"
+	return nil
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+	return scanner
+}
+
+// parseNDJSON reads not empty lines of the stream as events.
+func parseNDJSON(r io.Reader, onEvent func(models.StreamEvent) error) error {
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := onEvent(models.StreamEvent{Data: line}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// parseSSE reads events of the stream in text/event-stream format
+// (https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation).
+func parseSSE(r io.Reader, onEvent func(models.StreamEvent) error) error {
+	scanner := newLineScanner(r)
+	var eventType string
+	var data []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if data != nil {
+				if eventType == "" {
+					eventType = "message"
+				}
+				if err := onEvent(models.StreamEvent{Event: eventType, Data: strings.Join(data, "\n")}); err != nil {
+					return err
+				}
+			}
+			eventType, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment (usually used as keep-alive)
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch name {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return scanner.Err()
+}
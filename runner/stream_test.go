@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// streamHandler writes events and keeps the stream open until the client disconnects.
+func streamHandler(w http.ResponseWriter, r *http.Request) {
+	var contentType string
+	var events []string
+	switch r.URL.Path {
+	case "/sse":
+		contentType = "text/event-stream"
+		events = []string{
+			": keep-alive\n\n",
+			"event: progress\ndata: {\"percent\": 50}\n\n",
+			"event: progress\ndata: {\"percent\": 100}\n\n",
+			"id: 3\ndata: first line\ndata: second line\n\n",
+		}
+	case "/ndjson":
+		contentType = "application/x-ndjson"
+		events = []string{"{\"id\": 1}\n", "\n", "{\"id\": 2, \"tags\": [\"a\", \"b\"]}\n"}
+	case "/heartbeats":
+		contentType = "application/x-ndjson"
+		events = []string{"{\"id\": 1}\n", "{\"heartbeat\": true}\n", "{\"id\": 2}\n"}
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	flusher := w.(http.Flusher)
+	for _, event := range events {
+		_, _ = fmt.Fprint(w, event)
+		flusher.Flush()
+	}
+	if r.URL.Query().Get("close") != "" {
+		return
+	}
+	<-r.Context().Done()
+}
+
+func Test_Stream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(streamHandler))
+	defer srv.Close()
+
+	content := `
+- name: server-sent events
+  method: GET
+  path: /sse
+  stream:
+    events:
+      - event: progress
+        data: '{"percent": 50}'
+      - data: '{"percent": "$matchRegexp(^[0-9]+$)"}'
+      - event: message
+        data: "first line\nsecond line"
+  variables_to_set:
+    200:
+      percent: 0.percent
+
+- name: NDJSON in any order
+  method: GET
+  path: /ndjson
+  stream:
+    ignoreOrdering: true
+    events:
+      - data: '{"id": 1}'
+        comparisonParams:
+          ignoreArraysOrdering: true
+      - data: '{"id": 2, "tags": ["b", "a"]}'
+        comparisonParams:
+          ignoreArraysOrdering: true
+
+- name: unexpected event
+  method: GET
+  path: /sse
+  stream:
+    events:
+      - data: '{"percent": {{ $percent }}}'
+      - event: done
+        data: '{"percent": 100}'
+
+- name: unordered events don't match
+  method: GET
+  path: /ndjson
+  stream:
+    timeout: 100ms
+    ignoreOrdering: true
+    events:
+      - data: '{"id": 1}'
+      - data: '{"id": 3}'
+
+- name: unordered events with heartbeats
+  method: GET
+  path: /heartbeats
+  stream:
+    ignoreOrdering: true
+    events:
+      - data: '{"id": 2}'
+      - data: '{"id": 1}'
+
+- name: loose expectation doesn't take event of strict one
+  method: GET
+  path: /heartbeats
+  stream:
+    ignoreOrdering: true
+    events:
+      - data: '{"id": "$matchRegexp(^[0-9]+$)"}'
+      - data: '{"id": 1}'
+
+- name: events not received in time
+  method: GET
+  path: /ndjson
+  stream:
+    timeout: 100ms
+    events:
+      - data: '{"id": 1}'
+      - data: '$matchRegexp(.+)'
+      - data: '$matchRegexp(.+)'
+
+- name: stream closed
+  method: GET
+  path: /ndjson
+  query: ?close=1
+  stream:
+    format: ndjson
+    events:
+      - data: '{"id": 1}'
+      - data: '$matchRegexp(.+)'
+      - data: '$matchRegexp(.+)'
+
+- name: not a stream
+  method: GET
+  path: /unknown
+  stream:
+    events:
+      - data: '$matchRegexp(.+)'
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{Host: srv.URL}, content)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"unexpected event": "received event for '$.stream.events[1]': 'event type' does not match:\n" +
+			"     expected: done\n       actual: progress",
+		"unordered events don't match": "matching event for '$.stream.events[1]' was not received",
+		"events not received in time":  "path '$.stream': received 2 of 3 events within 100ms",
+		"stream closed":                "path '$.stream': stream was closed after 2 of 3 events",
+		"not a stream":                 "path '$.stream': events were not read, server responded with status 404 Not Found",
+	}, errs)
+}
@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/lansfy/gonkex/models"
+)
+
+// tagsExpression checks whether the set of test tags satisfies the selection expression.
+type tagsExpression func(tags map[string]bool) bool
+
+// tagsParser parses selection expressions like "smoke && !slow" or "(api || ui) && !flaky".
+//
+// Grammar:
+//
+//	or    = and { "||" and }
+//	and   = unary { "&&" unary }
+//	unary = "!" unary | "(" or ")" | tag
+type tagsParser struct {
+	tokens []string
+	pos    int
+}
+
+func tokenizeTags(expr string) []string {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(' || c == ')' || c == '!':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(expr[i:], "&&") || strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		default:
+			start := i
+			for i < len(expr) && !unicode.IsSpace(rune(expr[i])) && !strings.ContainsRune("()!&|", rune(expr[i])) {
+				i++
+			}
+			if start == i {
+				// single '&' or '|'
+				i++
+			}
+			tokens = append(tokens, expr[start:i])
+		}
+	}
+	return tokens
+}
+
+// parseTagsExpression parses the tags selection expression.
+// Empty expression matches any set of tags.
+func parseTagsExpression(expr string) (tagsExpression, error) {
+	p := &tagsParser{tokens: tokenizeTags(expr)}
+	if len(p.tokens) == 0 {
+		return func(map[string]bool) bool { return true }, nil
+	}
+
+	wrap := func(err error) error {
+		return fmt.Errorf("parse tags expression '%s': %w", expr, err)
+	}
+
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, wrap(err)
+	}
+	if p.pos != len(p.tokens) {
+		return nil, wrap(fmt.Errorf("unexpected '%s'", p.tokens[p.pos]))
+	}
+	return result, nil
+}
+
+func (p *tagsParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *tagsParser) parseOr() (tagsExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(tags map[string]bool) bool { return l(tags) || right(tags) }
+	}
+	return left, nil
+}
+
+func (p *tagsParser) parseAnd() (tagsExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(tags map[string]bool) bool { return l(tags) && right(tags) }
+	}
+	return left, nil
+}
+
+func (p *tagsParser) parseUnary() (tagsExpression, error) {
+	token := p.peek()
+	switch token {
+	case "":
+		return nil, errors.New("unexpected end of expression")
+	case "!":
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(tags map[string]bool) bool { return !inner(tags) }, nil
+	case "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing ')'")
+		}
+		p.pos++
+		return inner, nil
+	case ")", "&&", "||", "&", "|":
+		return nil, fmt.Errorf("unexpected '%s'", token)
+	}
+	p.pos++
+	return func(tags map[string]bool) bool { return tags[token] }, nil
+}
+
+// matchTags returns true if the tags of the test satisfy all expressions.
+func matchTags(t models.TestInterface, expressions []tagsExpression) bool {
+	tags := map[string]bool{}
+	for _, tag := range t.GetTags() {
+		tags[tag] = true
+	}
+	for _, expr := range expressions {
+		if !expr(tags) {
+			return false
+		}
+	}
+	return true
+}
+
+// tagsExpressions parses the tags expressions from options and command line.
+func (r *Runner) tagsExpressions() ([]tagsExpression, error) {
+	var expressions []tagsExpression
+	for _, str := range []string{r.config.Tags, tagsFlag} {
+		if strings.TrimSpace(str) == "" {
+			continue
+		}
+		expr, err := parseTagsExpression(str)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expr)
+	}
+	return expressions, nil
+}
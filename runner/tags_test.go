@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lansfy/gonkex/testloader/yaml_file"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseTagsExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		tags []string
+		want bool
+	}{
+		{"", nil, true},
+		{"smoke", []string{"smoke"}, true},
+		{"smoke", []string{"slow"}, false},
+		{"!smoke", nil, true},
+		{"smoke && !slow", []string{"smoke"}, true},
+		{"smoke && !slow", []string{"smoke", "slow"}, false},
+		{"smoke&&!slow", []string{"smoke", "slow"}, false},
+		{"api || ui", []string{"ui"}, true},
+		{"api || ui && slow", []string{"api"}, true},
+		{"(api || ui) && slow", []string{"api"}, false},
+		{"!(api || ui)", []string{"db"}, true},
+		{"!!api", []string{"api"}, true},
+		{"team:payments && v1.2", []string{"team:payments", "v1.2"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parseTagsExpression(tt.expr)
+			require.NoError(t, err)
+			tags := map[string]bool{}
+			for _, tag := range tt.tags {
+				tags[tag] = true
+			}
+			require.Equal(t, tt.want, expr(tags))
+		})
+	}
+}
+
+func Test_parseTagsExpression_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"smoke &&", "parse tags expression 'smoke &&': unexpected end of expression"},
+		{"smoke slow", "parse tags expression 'smoke slow': unexpected 'slow'"},
+		{"(smoke", "parse tags expression '(smoke': missing ')'"},
+		{"smoke)", "parse tags expression 'smoke)': unexpected ')'"},
+		{"smoke & slow", "parse tags expression 'smoke & slow': unexpected '&'"},
+		{"|| slow", "parse tags expression '|| slow': unexpected '||'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseTagsExpression(tt.expr)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_selectByTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     string
+		flag     string
+		expected string
+		wantErr  string
+	}{
+		{"no expression", "", "", ".....", ""},
+		{"only option", "smoke && !slow", "", ".ss.s", ""},
+		{"only flag", "", "regression", "sss..", ""},
+		{"option and flag", "smoke", "regression", "sss.s", ""},
+		{"wrong expression", "smoke ||", "", "", "parse tags expression 'smoke ||': unexpected end of expression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := newStatusServer()
+			srv := httptest.NewServer(obj)
+			defer srv.Close()
+
+			tagsFlag = tt.flag
+			defer func() { tagsFlag = "" }()
+
+			runner := New(
+				yaml_file.NewLoader("testdata/tags"),
+				&RunnerOpts{
+					Host: srv.URL,
+					Tags: tt.tags,
+				},
+			)
+			runner.AddOutput(obj)
+
+			err := runner.Run()
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"tags.yaml": tt.expected}, obj.output)
+		})
+	}
+}
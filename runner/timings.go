@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+)
+
+// timingsRecorder collects durations of the request phases with httptrace.
+// Trace callbacks can be called from different goroutines, so all fields are protected by mutex.
+type timingsRecorder struct {
+	mutex        sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timings      models.Timings
+}
+
+func newTimingsRecorder() *timingsRecorder {
+	return &timingsRecorder{
+		start: time.Now(),
+	}
+}
+
+func (r *timingsRecorder) record(f func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	f()
+}
+
+// attach returns the request which reports its phases to the recorder.
+func (r *timingsRecorder) attach(req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.record(func() { r.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.record(func() { r.timings.DNSLookup = time.Since(r.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			r.record(func() { r.connectStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			r.record(func() { r.timings.Connect = time.Since(r.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			r.record(func() { r.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.record(func() { r.timings.TLSHandshake = time.Since(r.tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			r.record(func() { r.timings.FirstByte = time.Since(r.start) })
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// finish returns the collected timings, total duration is measured from the creation of the recorder.
+// Phases are not traced for helper endpoints and custom clients which ignore request context.
+func (r *timingsRecorder) finish() models.Timings {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.timings.Total = time.Since(r.start)
+	return r.timings
+}
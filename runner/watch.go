@@ -0,0 +1,243 @@
+package runner
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+)
+
+// WatchOpts holds configuration options for the watch mode.
+type WatchOpts struct {
+	// Paths lists files and directories which are watched for changes
+	// (usually the tests and fixtures directories). Fixture files used by tests and files
+	// referenced by mocks with "file" strategy are watched automatically.
+	Paths []string
+
+	// Interval between checks of the watched files (500ms by default).
+	Interval time.Duration
+
+	// OnRun is called after every run with the list of changed files
+	// (empty for the first run) and the error returned by the run.
+	OnRun func(changed []string, err error)
+}
+
+// fixtureSuffixes are the extensions which are tried when fixture is referenced by name.
+var fixtureSuffixes = []string{"", ".yml", ".yaml"}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch runs all tests and then reruns affected tests every time watched files change.
+// Test is affected if its file, one of its fixtures or one of files used by its mocks was changed.
+// Mocks are kept running between runs. Watch blocks until the context is canceled.
+func (r *Runner) Watch(ctx context.Context, opts *WatchOpts) error {
+	o := WatchOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = 500 * time.Millisecond
+	}
+	if o.OnRun == nil {
+		o.OnRun = func([]string, error) {}
+	}
+
+	if filterFlag != "" {
+		setStringFilter(r.loader, filterFlag)
+	}
+
+	tests, err := r.loader.Load()
+	if err == nil {
+		err = r.runTests(tests, nil)
+	}
+	o.OnRun(nil, err)
+	snapshot := r.watchSnapshot(o.Paths, tests)
+
+	ticker := time.NewTicker(o.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current := r.watchSnapshot(o.Paths, tests)
+		changed := changedFiles(snapshot, current)
+		if len(changed) == 0 {
+			continue
+		}
+
+		tests, err = r.loader.Load()
+		if err != nil {
+			snapshot = current
+			o.OnRun(changed, err)
+			continue
+		}
+
+		affected := r.affectedTests(tests, changed)
+		snapshot = r.watchSnapshot(o.Paths, tests)
+		if len(affected) == 0 {
+			continue
+		}
+
+		err = r.runTests(tests, func(t models.TestInterface) bool {
+			return affected[t]
+		})
+		o.OnRun(changed, err)
+	}
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// watchSnapshot returns the state of all watched files.
+func (r *Runner) watchSnapshot(paths []string, tests []models.TestInterface) map[string]fileState {
+	snapshot := map[string]fileState{}
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			snapshot[absPath(path)] = fileState{info.ModTime(), info.Size()}
+		}
+	}
+
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				add(path)
+			}
+			return nil
+		})
+	}
+
+	for _, t := range tests {
+		add(t.GetFileName())
+		for _, name := range r.fixtureFiles(t) {
+			add(name)
+		}
+		for _, name := range mockFiles(t.ServiceMocks()) {
+			add(name)
+		}
+		for _, name := range mockFiles(beforeAllMocks(t)) {
+			add(name)
+		}
+	}
+	return snapshot
+}
+
+func changedFiles(before, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if prev, ok := before[path]; !ok || prev != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// fixtureFiles returns all possible paths of fixtures used by the test (including beforeAll section).
+func (r *Runner) fixtureFiles(t models.TestInterface) []string {
+	names := t.Fixtures()
+	if hook := t.BeforeAll(); hook != nil {
+		names = append(append([]string{}, names...), hook.Fixtures()...)
+	}
+
+	var files []string
+	for _, name := range names {
+		for _, suffix := range fixtureSuffixes {
+			files = append(files, filepath.Join(r.config.FixturesDir, name+suffix))
+		}
+	}
+	return files
+}
+
+// mockFiles returns files used by mocks with "file" strategy.
+func mockFiles(definition interface{}) []string {
+	var files []string
+	switch v := definition.(type) {
+	case map[string]interface{}:
+		if v["strategy"] == "file" {
+			if name, ok := v["filename"].(string); ok {
+				files = append(files, name)
+			}
+		}
+		for _, item := range v {
+			files = append(files, mockFiles(item)...)
+		}
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for key, item := range v {
+			if name, ok := key.(string); ok {
+				converted[name] = item
+			}
+		}
+		files = append(files, mockFiles(converted)...)
+	case []interface{}:
+		for _, item := range v {
+			files = append(files, mockFiles(item)...)
+		}
+	}
+	return files
+}
+
+// affectedTests returns tests which must be executed after the change of files.
+// If some changed file inside fixtures directory isn't used by any test directly
+// (for example, it is inherited by other fixtures), all tests with fixtures are affected.
+func (r *Runner) affectedTests(tests []models.TestInterface, changed []string) map[models.TestInterface]bool {
+	changedSet := map[string]bool{}
+	for _, path := range changed {
+		changedSet[path] = true
+	}
+	isChanged := func(paths []string) bool {
+		for _, path := range paths {
+			if changedSet[absPath(path)] {
+				return true
+			}
+		}
+		return false
+	}
+
+	usedFixtures := map[string]bool{}
+	for _, t := range tests {
+		for _, name := range r.fixtureFiles(t) {
+			usedFixtures[absPath(name)] = true
+		}
+	}
+
+	fixturesChanged := false
+	if r.config.FixturesDir != "" {
+		fixturesDir := absPath(r.config.FixturesDir) + string(filepath.Separator)
+		for _, path := range changed {
+			if strings.HasPrefix(path, fixturesDir) && !usedFixtures[path] {
+				fixturesChanged = true
+			}
+		}
+	}
+
+	affected := map[models.TestInterface]bool{}
+	for _, t := range tests {
+		fixtures := r.fixtureFiles(t)
+		if isChanged([]string{t.GetFileName()}) || isChanged(fixtures) ||
+			isChanged(mockFiles(t.ServiceMocks())) || isChanged(mockFiles(beforeAllMocks(t))) || (fixturesChanged && len(fixtures) != 0) {
+			affected[t] = true
+		}
+	}
+	return affected
+}
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/testloader/yaml_file"
+
+	"github.com/stretchr/testify/require"
+)
+
+const watchTestTemplate = `
+- name: %s
+  method: GET
+  path: /
+  response:
+    200: '{"calls": "$matchRegexp(^[0-9]+$)"}'
+`
+
+func writeWatchFile(t *testing.T, path, content string, mtime time.Time) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func Test_Watch(t *testing.T) {
+	dir := t.TempDir()
+	testsDir := filepath.Join(dir, "tests")
+	fixturesDir := filepath.Join(dir, "fixtures")
+	require.NoError(t, os.Mkdir(testsDir, 0o755))
+	require.NoError(t, os.Mkdir(fixturesDir, 0o755))
+
+	mtime := time.Now().Add(-time.Hour)
+	writeWatchFile(t, filepath.Join(testsDir, "a.yaml"), fmt.Sprintf(watchTestTemplate, "test a"), mtime)
+	writeWatchFile(t, filepath.Join(testsDir, "b.yaml"),
+		fmt.Sprintf(watchTestTemplate, "test b")+"  fixtures:\n    - fixture1\n", mtime)
+	writeWatchFile(t, filepath.Join(fixturesDir, "fixture1.yaml"), "tables: {}\n", mtime)
+	writeWatchFile(t, filepath.Join(fixturesDir, "base.yaml"), "tables: {}\n", mtime)
+
+	srv := httptest.NewServer(newStatusServer())
+	defer srv.Close()
+
+	var mutex sync.Mutex
+	var executed []string
+	runner := New(
+		yaml_file.NewLoader(testsDir),
+		&RunnerOpts{
+			Host:        srv.URL,
+			FixturesDir: fixturesDir,
+			DB:          &hooksStorage{},
+			TestHandler: func(test models.TestInterface, executor TestExecutor) (bool, error) {
+				result, err := executor(test)
+				if isTestWasSkipped(err) {
+					return false, nil
+				}
+				if err == nil && result.Passed() {
+					mutex.Lock()
+					executed = append(executed, test.GetName())
+					mutex.Unlock()
+				}
+				return false, err
+			},
+		},
+	)
+
+	type run struct {
+		changed  []string
+		executed []string
+		err      error
+	}
+	runs := make(chan run)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- runner.Watch(ctx, &WatchOpts{
+			Paths:    []string{testsDir, fixturesDir},
+			Interval: 10 * time.Millisecond,
+			OnRun: func(changed []string, err error) {
+				mutex.Lock()
+				names := executed
+				executed = nil
+				mutex.Unlock()
+				sort.Strings(names)
+				for i := range changed {
+					changed[i], _ = filepath.Rel(dir, changed[i])
+				}
+				runs <- run{changed, names, err}
+			},
+		})
+	}()
+
+	wait := func() run {
+		select {
+		case r := <-runs:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for run")
+		}
+		return run{}
+	}
+
+	require.Equal(t, run{nil, []string{"test a", "test b"}, nil}, wait())
+
+	mtime = mtime.Add(time.Minute)
+	writeWatchFile(t, filepath.Join(testsDir, "a.yaml"), fmt.Sprintf(watchTestTemplate, "test a2"), mtime)
+	require.Equal(t, run{[]string{"tests/a.yaml"}, []string{"test a2"}, nil}, wait())
+
+	mtime = mtime.Add(time.Minute)
+	writeWatchFile(t, filepath.Join(fixturesDir, "fixture1.yaml"), "tables: {}\n", mtime)
+	require.Equal(t, run{[]string{"fixtures/fixture1.yaml"}, []string{"test b"}, nil}, wait())
+
+	// fixture which isn't used directly affects all tests with fixtures
+	mtime = mtime.Add(time.Minute)
+	writeWatchFile(t, filepath.Join(fixturesDir, "base.yaml"), "tables: {}\n", mtime)
+	require.Equal(t, run{[]string{"fixtures/base.yaml"}, []string{"test b"}, nil}, wait())
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lansfy/gonkex/endpoint"
+	"github.com/lansfy/gonkex/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultWebSocketTimeout is used for receive steps without timeout.
+const defaultWebSocketTimeout = 5 * time.Second
+
+// makeWebSocketRequest connects to Host+path of the test and performs steps of the conversation.
+// The body of the result is JSON array with all received messages.
+func (r *Runner) makeWebSocketRequest(v models.TestInterface) (*models.Result, error) {
+	req, _, err := endpoint.NewRequest(r.config.Host, v)
+	if err != nil {
+		return nil, err
+	}
+
+	header := req.Header.Clone()
+	header.Del("Content-Type")
+	if req.Host != req.URL.Host {
+		header.Set("Host", req.Host)
+	}
+
+	wsURL := *req.URL
+	switch wsURL.Scheme {
+	case "https":
+		wsURL.Scheme = "wss"
+	default:
+		wsURL.Scheme = "ws"
+	}
+
+	opts := r.config.Client.merge(v.GetClientParams())
+	tlsConfig, err := newTLSConfig(&opts)
+	if err != nil {
+		return nil, fmt.Errorf("create WebSocket client: %w", err)
+	}
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyURL(r.config.HTTPProxyURL),
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: defaultWebSocketTimeout,
+	}
+
+	start := time.Now()
+	conn, resp, err := dialer.Dial(wsURL.String(), header)
+	if err != nil && resp == nil {
+		return nil, fmt.Errorf("connect to %s: %w", wsURL.String(), err)
+	}
+
+	result := &models.Result{
+		Path:                req.URL.Path,
+		Query:               req.URL.RawQuery,
+		ResponseStatusCode:  resp.StatusCode,
+		ResponseStatus:      resp.Status,
+		ResponseContentType: resp.Header.Get("Content-Type"),
+		ResponseHeaders:     resp.Header,
+		Test:                v,
+	}
+
+	if err != nil {
+		// server rejected the connection, so the response can be checked as usual
+		body, readErr := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+		result.ResponseBody = string(body)
+		result.Timings.Total = time.Since(start)
+		return result, nil
+	}
+	defer conn.Close()
+
+	var sent []string
+	var received []json.RawMessage
+	for idx, step := range v.GetWebSocket().Steps() {
+		if step.Receive() == "" {
+			sent = append(sent, step.Send())
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(step.Send())); err != nil {
+				result.WebSocket = append(result.WebSocket, models.WebSocketMessage{
+					Step:  idx,
+					Error: fmt.Errorf("send message: %w", err),
+				})
+				break
+			}
+			continue
+		}
+
+		message, err := receiveMessage(conn, step.Timeout())
+		result.WebSocket = append(result.WebSocket, models.WebSocketMessage{
+			Step:    idx,
+			Message: message,
+			Error:   err,
+		})
+		if err != nil {
+			// state of the connection is unknown, so the rest of steps is skipped
+			break
+		}
+		received = append(received, toRawJSON(message))
+	}
+
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	result.Timings.Total = time.Since(start)
+
+	if received == nil {
+		received = []json.RawMessage{}
+	}
+	body, err := json.Marshal(received)
+	if err != nil {
+		return nil, err
+	}
+	result.RequestBody = strings.Join(sent, "\n")
+	result.ResponseBody = string(body)
+	result.ResponseContentType = "application/json"
+	return result, nil
+}
+
+func receiveMessage(conn *websocket.Conn, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		timeout = defaultWebSocketTimeout
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+
+	_, message, err := conn.ReadMessage()
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return string(message), nil
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "", fmt.Errorf("message was not received within %s", timeout)
+	case websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway):
+		return "", errors.New("connection was closed by server before message was received")
+	default:
+		return "", fmt.Errorf("receive message: %w", err)
+	}
+}
+
+// toRawJSON returns JSON message as is and encodes other messages as JSON strings.
+func toRawJSON(message string) json.RawMessage {
+	if json.Valid([]byte(message)) {
+		return json.RawMessage(message)
+	}
+	data, _ := json.Marshal(message)
+	return data
+}
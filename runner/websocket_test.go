@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// chatHandler answers on subscription with two events and echoes other messages.
+func chatHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "secret" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var replies []string
+		switch string(message) {
+		case `{"action": "subscribe"}`:
+			replies = []string{`{"status": "subscribed"}`, `{"event": "user_created", "id": 7}`}
+		case "silence":
+		default:
+			replies = []string{fmt.Sprintf("echo: %s", message)}
+		}
+		for _, reply := range replies {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(reply)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func Test_WebSocket(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(chatHandler))
+	defer srv.Close()
+
+	content := `
+- name: subscription
+  path: /ws
+  headers:
+    Authorization: secret
+  websocket:
+    steps:
+      - send: '{"action": "subscribe"}'
+      - receive: '{"status": "subscribed"}'
+      - receive: '{"event": "$matchRegexp(^user_)"}'
+  variables_to_set:
+    101:
+      userID: 1.id
+
+- name: echo with variable
+  path: /ws
+  headers:
+    Authorization: secret
+  websocket:
+    steps:
+      - send: 'user {{ $userID }}'
+      - receive: 'echo: user 7'
+
+- name: unexpected message
+  path: /ws
+  headers:
+    Authorization: secret
+  websocket:
+    steps:
+      - send: hello
+      - receive: 'echo: bye'
+
+- name: message not received
+  path: /ws
+  headers:
+    Authorization: secret
+  websocket:
+    timeout: 100ms
+    steps:
+      - send: silence
+      - receive: something
+      - send: hello
+      - receive: 'echo: hello'
+
+- name: rejected connection
+  path: /ws
+  websocket:
+    steps:
+      - send: hello
+  response:
+    401: "unauthorized\n"
+`
+
+	errs, err := runClientTests(t, &RunnerOpts{Host: srv.URL}, content)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"unexpected message":   "received message for '$.websocket.steps[1]': path '$': values do not match:\n     expected: echo: bye\n       actual: echo: hello",
+		"message not received": "path '$.websocket.steps[1]': message was not received within 100ms",
+	}, errs)
+}
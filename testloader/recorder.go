@@ -0,0 +1,19 @@
+package testloader
+
+import (
+	"github.com/lansfy/gonkex/models"
+)
+
+// RecorderInterface defines the interface for writing actual responses of the service
+// back to the source of test definitions (record mode).
+type RecorderInterface interface {
+	// NeedsRecording returns true if the expected response of the test must be recorded.
+	NeedsRecording(t models.TestInterface) bool
+
+	// Record stores the response from the result as the expected response of the test.
+	//
+	// Returns:
+	// - models.TestInterface: A copy of the test with the recorded expectations
+	// - error: An error if the test source can't be updated
+	Record(t models.TestInterface, result *models.Result) (models.TestInterface, error)
+}
@@ -0,0 +1,72 @@
+package yaml_file
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sortByDependencies validates dependsOn declarations of the file and orders definitions,
+// so every test is placed after the tests it depends on. Tests without dependencies
+// keep the order of declaration.
+func sortByDependencies(defs []*TestDefinition) ([]*TestDefinition, error) {
+	byName := map[string]*TestDefinition{}
+	duplicates := map[string]bool{}
+	hasDependencies := false
+	for _, def := range defs {
+		if _, ok := byName[def.Name]; ok {
+			duplicates[def.Name] = true
+		}
+		byName[def.Name] = def
+		hasDependencies = hasDependencies || len(def.DependsOn) != 0
+	}
+
+	if !hasDependencies {
+		return defs, nil
+	}
+
+	for _, def := range defs {
+		for _, name := range def.DependsOn {
+			if _, ok := byName[name]; !ok {
+				return nil, fmt.Errorf("test '%s': dependency '%s' not found in the file", def.Name, name)
+			}
+			if duplicates[name] {
+				return nil, fmt.Errorf("test '%s': dependency '%s' is ambiguous, several tests have this name", def.Name, name)
+			}
+		}
+	}
+
+	result := make([]*TestDefinition, 0, len(defs))
+	placed := map[*TestDefinition]bool{}
+	visiting := map[*TestDefinition]bool{}
+	var path []string
+
+	var place func(def *TestDefinition) error
+	place = func(def *TestDefinition) error {
+		if placed[def] {
+			return nil
+		}
+		path = append(path, def.Name)
+		defer func() { path = path[:len(path)-1] }()
+
+		if visiting[def] {
+			return fmt.Errorf("test '%s': circular dependency: %s", def.Name, strings.Join(path, " -> "))
+		}
+		visiting[def] = true
+
+		for _, name := range def.DependsOn {
+			if err := place(byName[name]); err != nil {
+				return err
+			}
+		}
+		placed[def] = true
+		result = append(result, def)
+		return nil
+	}
+
+	for _, def := range defs {
+		if err := place(def); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
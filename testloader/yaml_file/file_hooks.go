@@ -0,0 +1,52 @@
+package yaml_file
+
+import (
+	"errors"
+	"reflect"
+)
+
+func isFileHookDefinition(def *TestDefinition) bool {
+	return def.BeforeAll != nil || def.AfterAll != nil
+}
+
+// extractFileHooks separates file-level beforeAll/afterAll items from the test definitions.
+func extractFileHooks(defs []*TestDefinition) (*fileHooks, []*TestDefinition, error) {
+	hooks := &fileHooks{}
+	tests := []*TestDefinition{}
+	for _, def := range defs {
+		if !isFileHookDefinition(def) {
+			tests = append(tests, def)
+			continue
+		}
+
+		if !reflect.DeepEqual(*def, TestDefinition{
+			BeforeAll:  def.BeforeAll,
+			AfterAll:   def.AfterAll,
+			LineNumber: def.LineNumber,
+		}) {
+			return nil, nil, errors.New("beforeAll/afterAll must be declared as a separate item without other test keys")
+		}
+
+		if def.BeforeAll != nil {
+			if hooks.beforeAll != nil {
+				return nil, nil, errors.New("beforeAll declared more than once")
+			}
+			hooks.beforeAll = def.BeforeAll
+		}
+
+		if def.AfterAll != nil {
+			if hooks.afterAll != nil {
+				return nil, nil, errors.New("afterAll declared more than once")
+			}
+			if len(def.AfterAll.Mocks) != 0 || len(def.AfterAll.Variables) != 0 {
+				return nil, nil, errors.New("afterAll supports only 'fixtures' and 'script' keys")
+			}
+			hooks.afterAll = def.AfterAll
+		}
+	}
+
+	if hooks.beforeAll == nil && hooks.afterAll == nil {
+		return nil, tests, nil
+	}
+	return hooks, tests, nil
+}
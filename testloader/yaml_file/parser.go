@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"text/template"
@@ -22,6 +23,11 @@ const (
 
 var gonkexProtectTemplate = regexp.MustCompile(`{{\s*\$`)
 
+var grpcMethodRx = regexp.MustCompile(`^/?[^/\s]+/[^/\s]+$`)
+
+// tags are used in selection expressions, so they can't contain spaces and operators
+var tagRx = regexp.MustCompile(`^[^\s!&|()]+$`)
+
 func parseTestDefinitionContent(opts *LoaderOpts, absPath string, data []byte) ([]models.TestInterface, error) {
 	testDefinitions, err := opts.CustomFileParse(absPath, data)
 	if err != nil {
@@ -32,13 +38,34 @@ func parseTestDefinitionContent(opts *LoaderOpts, absPath string, data []byte) (
 		return fmt.Errorf("process '%s': %w", absPath, err)
 	}
 
+	hooks, testDefinitions, err := extractFileHooks(testDefinitions)
+	if err != nil {
+		return nil, wrap(err)
+	}
+
+	testDefinitions, err = sortByDependencies(testDefinitions)
+	if err != nil {
+		return nil, wrap(err)
+	}
+
 	tests := []*testImpl{}
+	generatedNames := map[string][]string{}
 	for _, item := range testDefinitions {
 		testCases, err := makeTestFromDefinition(opts, absPath, item)
 		if err != nil {
 			return nil, wrap(err)
 		}
 
+		// dependencies are always declared earlier after sorting
+		var dependencies []string
+		for _, name := range item.DependsOn {
+			dependencies = append(dependencies, generatedNames[name]...)
+		}
+
+		for _, t := range testCases {
+			t.Dependencies = dependencies
+			generatedNames[item.Name] = append(generatedNames[item.Name], t.Name)
+		}
 		tests = append(tests, testCases...)
 	}
 
@@ -47,6 +74,10 @@ func parseTestDefinitionContent(opts *LoaderOpts, absPath string, data []byte) (
 		tests[len(tests)-1].LastTest = true
 	}
 
+	for _, t := range tests {
+		t.Hooks = hooks
+	}
+
 	// process persistent mocks
 	err = updateShareState(tests)
 	if err != nil {
@@ -128,7 +159,9 @@ func appendLineNumber(content []byte, defs []*TestDefinition) {
 	scanner := bufio.NewScanner(bytes.NewReader(content))
 	for scanner.Scan() {
 		counter++
-		if strings.HasPrefix(scanner.Text(), "- name:") {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "- name:") || strings.HasPrefix(line, "- beforeAll:") ||
+			strings.HasPrefix(line, "- afterAll:") {
 			linesN = append(linesN, counter)
 		}
 	}
@@ -198,10 +231,46 @@ func validateDbChecks(test *testImpl) error {
 		if item.DbQueryString() == "" && len(item.DbResponseJson()) != 0 {
 			return errors.New("'dbResponse' found without corresponding 'dbQuery'")
 		}
+		if item.GetEventually().Timeout() == 0 && item.GetEventually().Interval() != 0 {
+			return errors.New("'eventually.interval' found without corresponding 'eventually.timeout'")
+		}
 	}
 	return nil
 }
 
+// readResponseFiles reads expected bodies from files listed in responseFile section.
+// Relative paths are resolved from the directory of the test file.
+func readResponseFiles(filePath string, def *TestDefinition) error {
+	if len(def.ResponseFile) == 0 {
+		return nil
+	}
+
+	responses := map[int]string{}
+	for status, body := range def.Response {
+		responses[status] = body
+	}
+
+	files := map[int]string{}
+	for status, name := range def.ResponseFile {
+		if _, ok := responses[status]; ok {
+			return fmt.Errorf("response for status %d is declared in both 'response' and 'responseFile'", status)
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(filePath), name)
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("read response file: %w", err)
+		}
+		responses[status] = string(content)
+		files[status] = name
+	}
+
+	def.Response = responses
+	def.ResponseFile = files
+	return nil
+}
+
 // Make tests from the given test definition.
 func makeTestFromDefinition(opts *LoaderOpts, filePath string, def *TestDefinition) ([]*testImpl, error) {
 	wrap := func(err error) error {
@@ -212,6 +281,38 @@ func makeTestFromDefinition(opts *LoaderOpts, filePath string, def *TestDefiniti
 		return nil, wrap(errors.New("mixing old dbQuery/dbResponse with dbChecks in a single test is forbidden"))
 	}
 
+	if err := validateTags(def.Tags); err != nil {
+		return nil, wrap(err)
+	}
+
+	if err := validateGRPC(def); err != nil {
+		return nil, wrap(err)
+	}
+
+	if err := validateWebSocket(def); err != nil {
+		return nil, wrap(err)
+	}
+
+	if err := validateStream(def); err != nil {
+		return nil, wrap(err)
+	}
+
+	if err := validateSteps(def); err != nil {
+		return nil, wrap(err)
+	}
+
+	if err := validateMessages(def); err != nil {
+		return nil, wrap(err)
+	}
+
+	if err := validateMockRequests(def); err != nil {
+		return nil, wrap(err)
+	}
+
+	if err := readResponseFiles(filePath, def); err != nil {
+		return nil, wrap(err)
+	}
+
 	// test definition has no cases, so using request/response as is
 	if len(def.Cases) == 0 {
 		test := makeOneTest(filePath, def)
@@ -250,6 +351,11 @@ func makeTestFromDefinition(opts *LoaderOpts, filePath string, def *TestDefiniti
 			return fmt.Errorf("test '%s': %w", test.Name, err)
 		}
 
+		if err := validateTags(testCase.Tags); err != nil {
+			return nil, wrap(err)
+		}
+		test.Tags = mergeTags(def.Tags, testCase.Tags)
+
 		var err error
 		// substitute RequestArgs to different parts of request
 		test.TestDefinition.Path, err = substituteArgs(opts, "path", def.Path, testCase.RequestArgs)
@@ -277,6 +383,28 @@ func makeTestFromDefinition(opts *LoaderOpts, filePath string, def *TestDefiniti
 			return nil, wrap(err)
 		}
 
+		if def.WebSocket != nil {
+			test.WebSocket, err = substituteWebSocketArgs(opts, def.WebSocket, testCase.RequestArgs)
+			if err != nil {
+				return nil, wrap(err)
+			}
+		}
+
+		if len(def.Steps) != 0 {
+			test.Steps, err = substituteStepsArgs(opts, def.Steps, testCase.RequestArgs)
+			if err != nil {
+				return nil, wrap(err)
+			}
+		}
+
+		if def.GRPC != nil {
+			test.GRPC = &GRPCDefinition{Method: def.GRPC.Method, Timeout: def.GRPC.Timeout}
+			test.GRPC.Metadata, err = substituteArgsToMap(opts, "grpc.metadata", def.GRPC.Metadata, testCase.RequestArgs)
+			if err != nil {
+				return nil, wrap(err)
+			}
+		}
+
 		// substitute ResponseArgs to different parts of response
 		responses := map[int]string{}
 		for status, tpl := range def.Response {
@@ -367,9 +495,10 @@ func makeOneTest(filePath string, def *TestDefinition) *testImpl {
 
 	for _, check := range def.DbChecks {
 		dbChecks = append(dbChecks, &dbCheck{
-			query:    check.DbQuery,
-			response: check.DbResponse,
-			params:   check.ComparisonParams,
+			query:      check.DbQuery,
+			response:   check.DbResponse,
+			params:     check.ComparisonParams,
+			eventually: check.Eventually,
 		})
 	}
 	test.DbChecks = dbChecks
@@ -412,8 +541,9 @@ func readDatabaseCheck(opts *LoaderOpts, def *TestDefinition, testCase *CaseData
 		}
 
 		c := &dbCheck{
-			query:  query,
-			params: check.ComparisonParams,
+			query:      query,
+			params:     check.ComparisonParams,
+			eventually: check.Eventually,
 		}
 		for i, tpl := range check.DbResponse {
 			responseString, err := substituteArgs(opts,
@@ -429,6 +559,169 @@ func readDatabaseCheck(opts *LoaderOpts, def *TestDefinition, testCase *CaseData
 	return dbChecks, nil
 }
 
+// validateGRPC checks that gRPC test doesn't contain parameters of HTTP request.
+func validateGRPC(def *TestDefinition) error {
+	if def.GRPC == nil {
+		return nil
+	}
+	if !grpcMethodRx.MatchString(def.GRPC.Method) {
+		return fmt.Errorf("invalid gRPC method %q: expected format 'package.Service/Method'", def.GRPC.Method)
+	}
+
+	httpFields := []struct {
+		name string
+		used bool
+	}{
+		{"method", def.Method != ""},
+		{"path", def.Path != ""},
+		{"query", def.Query != ""},
+		{"form", def.Form != nil},
+		{"headers", len(def.Headers) != 0},
+		{"cookies", len(def.Cookies) != 0},
+		{"client", def.Client != ClientParams{}},
+	}
+	for _, field := range httpFields {
+		if field.used {
+			return fmt.Errorf("field '%s' can't be used in gRPC test", field.name)
+		}
+	}
+	return nil
+}
+
+// validateWebSocket checks steps of WebSocket test.
+func validateWebSocket(def *TestDefinition) error {
+	if def.WebSocket == nil {
+		return nil
+	}
+	if def.GRPC != nil {
+		return errors.New("sections 'grpc' and 'websocket' can't be used together")
+	}
+	if def.Method != "" && !strings.EqualFold(def.Method, "GET") {
+		return fmt.Errorf("method %s can't be used in WebSocket test", def.Method)
+	}
+	if def.Request != "" {
+		return errors.New("field 'request' can't be used in WebSocket test")
+	}
+	if def.Form != nil {
+		return errors.New("field 'form' can't be used in WebSocket test")
+	}
+	if len(def.WebSocket.Steps) == 0 {
+		return errors.New("WebSocket test must contain at least one step")
+	}
+	for idx, step := range def.WebSocket.Steps {
+		if (step.Send == nil) == (step.Receive == nil) {
+			return fmt.Errorf("step websocket.steps[%d] must contain either 'send' or 'receive'", idx)
+		}
+		if step.Receive != nil && *step.Receive == "" {
+			return fmt.Errorf("field 'receive' of step websocket.steps[%d] can't be empty", idx)
+		}
+	}
+	return nil
+}
+
+// validateStream checks expected events of the streaming response.
+func validateStream(def *TestDefinition) error {
+	if def.Stream == nil {
+		return nil
+	}
+	if def.GRPC != nil || def.WebSocket != nil {
+		return errors.New("section 'stream' can't be used in gRPC or WebSocket test")
+	}
+	switch def.Stream.Format {
+	case "", "sse", "ndjson":
+	default:
+		return fmt.Errorf("unknown stream format %q, expected 'sse' or 'ndjson'", def.Stream.Format)
+	}
+	if len(def.Stream.Events) == 0 {
+		return errors.New("section 'stream' must contain at least one event")
+	}
+	if def.Stream.Format == "ndjson" {
+		for idx, event := range def.Stream.Events {
+			if event.Event != "" {
+				return fmt.Errorf("field 'event' of stream.events[%d] can't be used with 'ndjson' format", idx)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMessages checks sections of the message bus.
+func validateMessages(def *TestDefinition) error {
+	for idx, item := range def.PublishMessages {
+		if item.Topic == "" {
+			return fmt.Errorf("field 'topic' of publishMessages[%d] can't be empty", idx)
+		}
+	}
+	for idx, item := range def.MessageChecks {
+		if item.Topic == "" {
+			return fmt.Errorf("field 'topic' of messageChecks[%d] can't be empty", idx)
+		}
+	}
+	return nil
+}
+
+// validateMockRequests checks section of the requests expected to be received by mocks.
+func validateMockRequests(def *TestDefinition) error {
+	for name, item := range def.MockRequests {
+		if item.Count == nil && item.Requests == nil {
+			return fmt.Errorf("section 'mockRequests.%s' must contain 'count' or 'requests'", name)
+		}
+		if item.Count != nil && *item.Count < 0 {
+			return fmt.Errorf("field 'count' of mockRequests.%s can't be negative", name)
+		}
+	}
+	return nil
+}
+
+func substituteWebSocketArgs(opts *LoaderOpts, def *WebSocketDefinition,
+	args map[string]interface{}) (*WebSocketDefinition, error) {
+	res := &WebSocketDefinition{
+		Timeout: def.Timeout,
+		Steps:   make([]WebSocketStepDefinition, len(def.Steps)),
+	}
+	for idx, step := range def.Steps {
+		if step.Send != nil {
+			value, err := substituteArgs(opts, fmt.Sprintf("websocket.steps[%d].send", idx), *step.Send, args)
+			if err != nil {
+				return nil, err
+			}
+			step.Send = &value
+		}
+		res.Steps[idx] = step
+	}
+	return res, nil
+}
+
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if !tagRx.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q: tag can't be empty or contain spaces and '!&|()' characters", tag)
+		}
+	}
+	return nil
+}
+
+// mergeTags returns tags of the test extended with tags of the case (without duplicates).
+func mergeTags(testTags, caseTags []string) []string {
+	if len(caseTags) == 0 {
+		return testTags
+	}
+	result := append([]string{}, testTags...)
+	for _, tag := range caseTags {
+		found := false
+		for _, existing := range result {
+			if existing == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
 func cloneVariables(s map[string]string) map[string]string {
 	clone := map[string]string{}
 	for k, v := range s {
@@ -135,6 +135,45 @@ type databaseCheckResult struct {
 	DbQueryString       string                 `yaml:"DbQueryString"`
 	DbResponseJson      []string               `yaml:"DbResponseJson"`
 	GetComparisonParams comparisonParamsResult `yaml:"GetComparisonParams"`
+	GetEventually       eventuallyResult       `yaml:"GetEventually"`
+}
+
+type busMessageResult struct {
+	Key     string            `yaml:"Key"`
+	Value   string            `yaml:"Value"`
+	Headers map[string]string `yaml:"Headers"`
+}
+
+type topicMessagesResult struct {
+	Topic    string             `yaml:"Topic"`
+	Messages []busMessageResult `yaml:"Messages"`
+}
+
+type messageCheckResult struct {
+	Topic               string                 `yaml:"Topic"`
+	Messages            []busMessageResult     `yaml:"Messages"`
+	Timeout             time.Duration          `yaml:"Timeout"`
+	GetComparisonParams comparisonParamsResult `yaml:"GetComparisonParams"`
+}
+
+type mockRequestResult struct {
+	Method  string            `yaml:"Method"`
+	Path    string            `yaml:"Path"`
+	Query   string            `yaml:"Query"`
+	Headers map[string]string `yaml:"Headers"`
+	Body    string            `yaml:"Body"`
+}
+
+type mockRequestsCheckResult struct {
+	ServiceName         string                 `yaml:"ServiceName"`
+	Count               *int                   `yaml:"Count"`
+	Requests            []mockRequestResult    `yaml:"Requests"`
+	GetComparisonParams comparisonParamsResult `yaml:"GetComparisonParams"`
+}
+
+type eventuallyResult struct {
+	Timeout  time.Duration `yaml:"Timeout"`
+	Interval time.Duration `yaml:"Interval"`
 }
 
 type retryPolicyResult struct {
@@ -158,6 +197,51 @@ type mocksResult struct {
 	SkipMocksResetAfterTest  bool `yaml:"SkipMocksResetAfterTest"`
 }
 
+type clientParamsResult struct {
+	CAFile     string `yaml:"CAFile"`
+	CertFile   string `yaml:"CertFile"`
+	KeyFile    string `yaml:"KeyFile"`
+	ServerName string `yaml:"ServerName"`
+	Protocol   string `yaml:"Protocol"`
+}
+
+type grpcResult struct {
+	FullMethod string            `yaml:"FullMethod"`
+	Metadata   map[string]string `yaml:"Metadata"`
+	Timeout    time.Duration     `yaml:"Timeout"`
+}
+
+type webSocketStepResult struct {
+	Send                string                 `yaml:"Send"`
+	Receive             string                 `yaml:"Receive"`
+	Timeout             time.Duration          `yaml:"Timeout"`
+	GetComparisonParams comparisonParamsResult `yaml:"GetComparisonParams"`
+}
+
+type webSocketResult struct {
+	Steps []webSocketStepResult `yaml:"Steps"`
+}
+
+type streamEventResult struct {
+	Event               string                 `yaml:"Event"`
+	Data                string                 `yaml:"Data"`
+	GetComparisonParams comparisonParamsResult `yaml:"GetComparisonParams"`
+}
+
+type streamResult struct {
+	Format         string              `yaml:"Format"`
+	Timeout        time.Duration       `yaml:"Timeout"`
+	IgnoreOrdering bool                `yaml:"IgnoreOrdering"`
+	Events         []streamEventResult `yaml:"Events"`
+}
+
+type fileHookResult struct {
+	Fixtures     []string               `yaml:"Fixtures"`
+	Script       scriptResult           `yaml:"Script"`
+	GetVariables map[string]string      `yaml:"GetVariables"`
+	ServiceMocks map[string]interface{} `yaml:"ServiceMocks"`
+}
+
 type TestInterfaceResult struct {
 	Error               string                    `yaml:"Error"`
 	GetName             string                    `yaml:"GetName"`
@@ -171,13 +255,23 @@ type TestInterfaceResult struct {
 	GetRequest          string                    `yaml:"GetRequest"`
 	GetForm             *formResult               `yaml:"GetForm"`
 	GetMeta             map[string]interface{}    `yaml:"GetMeta"`
+	GetTags             []string                  `yaml:"GetTags"`
+	GetDependencies     []string                  `yaml:"GetDependencies"`
 	GetStatus           models.Status             `yaml:"GetStatus"`
 	GetResponses        map[int]string            `yaml:"GetResponses"`
 	GetResponseHeaders  map[int]map[string]string `yaml:"GetResponseHeaders"`
 	Fixtures            []string                  `yaml:"Fixtures"`
 	GetDatabaseChecks   []databaseCheckResult     `yaml:"GetDatabaseChecks"`
+	PublishMessages     []topicMessagesResult     `yaml:"PublishMessages"`
+	GetMessageChecks    []messageCheckResult      `yaml:"GetMessageChecks"`
+	GetMockRequests     []mockRequestsCheckResult `yaml:"GetMockRequests"`
 	GetComparisonParams comparisonParamsResult    `yaml:"GetComparisonParams"`
 	GetRetryPolicy      retryPolicyResult         `yaml:"GetRetryPolicy"`
+	GetClientParams     clientParamsResult        `yaml:"GetClientParams"`
+	GetGRPC             *grpcResult               `yaml:"GetGRPC"`
+	GetWebSocket        *webSocketResult          `yaml:"GetWebSocket"`
+	GetStream           *streamResult             `yaml:"GetStream"`
+	GetSteps            []TestInterfaceResult     `yaml:"GetSteps"`
 	ServiceMocks        map[string]interface{}    `yaml:"ServiceMocks"`
 	ServiceMocksParams  mocksResult               `yaml:"ServiceMocksParams"`
 	Pause               time.Duration             `yaml:"Pause"`
@@ -194,6 +288,62 @@ type TestInterfaceResult struct {
 	FirstTestInFile bool   `yaml:"FirstTestInFile"`
 	LastTestInFile  bool   `yaml:"LastTestInFile"`
 	OneOfCase       bool   `yaml:"OneOfCase"`
+
+	BeforeAll *fileHookResult `yaml:"BeforeAll"`
+	AfterAll  *fileHookResult `yaml:"AfterAll"`
+}
+
+func compareClientParams(t *testing.T, expected *clientParamsResult, actual models.ClientParams) {
+	assert.Equal(t, expected.CAFile, actual.CAFile(), "GetClientParams.CAFile returns wrong value")
+	assert.Equal(t, expected.CertFile, actual.CertFile(), "GetClientParams.CertFile returns wrong value")
+	assert.Equal(t, expected.KeyFile, actual.KeyFile(), "GetClientParams.KeyFile returns wrong value")
+	assert.Equal(t, expected.ServerName, actual.ServerName(), "GetClientParams.ServerName returns wrong value")
+	assert.Equal(t, expected.Protocol, actual.Protocol(), "GetClientParams.Protocol returns wrong value")
+}
+
+func compareGRPC(t *testing.T, expected *grpcResult, actual models.GRPCParams) {
+	if expected == nil {
+		require.Nil(t, actual, "GetGRPC returns not-nil value")
+		return
+	}
+	require.NotNil(t, actual, "GetGRPC returns nil value")
+	assert.Equal(t, expected.FullMethod, actual.FullMethod(), "GetGRPC.FullMethod returns wrong value")
+	assert.Equal(t, expected.Metadata, actual.Metadata(), "GetGRPC.Metadata returns wrong value")
+	assert.Equal(t, expected.Timeout, actual.Timeout(), "GetGRPC.Timeout returns wrong value")
+}
+
+func compareWebSocket(t *testing.T, expected *webSocketResult, actual models.WebSocketParams) {
+	if expected == nil {
+		require.Nil(t, actual, "GetWebSocket returns not-nil value")
+		return
+	}
+	require.NotNil(t, actual, "GetWebSocket returns nil value")
+	steps := actual.Steps()
+	require.Len(t, steps, len(expected.Steps), "GetWebSocket.Steps has different number of elements")
+	for i, step := range steps {
+		assert.Equal(t, expected.Steps[i].Send, step.Send(), "Steps[%d].Send returns wrong value", i)
+		assert.Equal(t, expected.Steps[i].Receive, step.Receive(), "Steps[%d].Receive returns wrong value", i)
+		assert.Equal(t, expected.Steps[i].Timeout, step.Timeout(), "Steps[%d].Timeout returns wrong value", i)
+		compareComparisonParams(t, expected.Steps[i].GetComparisonParams, step.GetComparisonParams())
+	}
+}
+
+func compareStream(t *testing.T, expected *streamResult, actual models.StreamParams) {
+	if expected == nil {
+		require.Nil(t, actual, "GetStream returns not-nil value")
+		return
+	}
+	require.NotNil(t, actual, "GetStream returns nil value")
+	assert.Equal(t, expected.Format, actual.Format(), "GetStream.Format returns wrong value")
+	assert.Equal(t, expected.Timeout, actual.Timeout(), "GetStream.Timeout returns wrong value")
+	assert.Equal(t, expected.IgnoreOrdering, actual.IgnoreOrdering(), "GetStream.IgnoreOrdering returns wrong value")
+	events := actual.Events()
+	require.Len(t, events, len(expected.Events), "GetStream.Events has different number of elements")
+	for i, event := range events {
+		assert.Equal(t, expected.Events[i].Event, event.Event(), "Events[%d].Event returns wrong value", i)
+		assert.Equal(t, expected.Events[i].Data, event.Data(), "Events[%d].Data returns wrong value", i)
+		compareComparisonParams(t, expected.Events[i].GetComparisonParams, event.GetComparisonParams())
+	}
 }
 
 func compareTestInterface(t *testing.T, expected *TestInterfaceResult, actual models.TestInterface) {
@@ -260,11 +410,27 @@ func compareTestInterface(t *testing.T, expected *TestInterfaceResult, actual mo
 		assert.Nil(t, actualValue, "GetMeta return something for random key")
 	}
 
+	assert.Equal(t, expected.GetTags, actual.GetTags(), "GetTags returns wrong value")
+	assert.Equal(t, expected.GetDependencies, actual.GetDependencies(), "GetDependencies returns wrong value")
 	assert.Equal(t, expected.Fixtures, actual.Fixtures(), "Fixtures returns wrong value")
 
 	compareDatabaseCheckResult(t, expected.GetDatabaseChecks, actual.GetDatabaseChecks())
+	compareMessages(t, expected.PublishMessages, expected.GetMessageChecks, actual)
+	compareMockRequests(t, expected.GetMockRequests, actual.GetMockRequests())
 	compareComparisonParams(t, expected.GetComparisonParams, actual.GetComparisonParams())
 	compareRetryPolicy(t, expected.GetRetryPolicy, actual.GetRetryPolicy())
+	compareClientParams(t, &expected.GetClientParams, actual.GetClientParams())
+	compareGRPC(t, expected.GetGRPC, actual.GetGRPC())
+	compareWebSocket(t, expected.GetWebSocket, actual.GetWebSocket())
+	compareStream(t, expected.GetStream, actual.GetStream())
+
+	steps := actual.GetSteps()
+	require.Len(t, steps, len(expected.GetSteps), "GetSteps has different number of elements")
+	for idx := range steps {
+		t.Run(fmt.Sprintf("step #%d", idx), func(t *testing.T) {
+			compareTestInterface(t, &expected.GetSteps[idx], steps[idx])
+		})
+	}
 
 	assert.Equal(t, expected.ServiceMocks, actual.ServiceMocks(), "ServiceMocks returns wrong value")
 	compareServiceMocksParams(t, expected.ServiceMocksParams, actual.ServiceMocksParams())
@@ -302,6 +468,22 @@ func compareTestInterface(t *testing.T, expected *TestInterfaceResult, actual mo
 	assert.Equal(t, expected.FirstTestInFile, actual.FirstTestInFile(), "FirstTestInFile returns wrong value")
 	assert.Equal(t, expected.LastTestInFile, actual.LastTestInFile(), "LastTestInFile returns wrong value")
 	assert.Equal(t, expected.OneOfCase, actual.OneOfCase(), "OneOfCase returns wrong value")
+
+	compareFileHook(t, expected.BeforeAll, actual.BeforeAll())
+	compareFileHook(t, expected.AfterAll, actual.AfterAll())
+}
+
+func compareFileHook(t *testing.T, expected *fileHookResult, actual models.FileHook) {
+	if expected == nil {
+		require.Nil(t, actual, "file hook must be nil")
+		return
+	}
+	require.NotNil(t, actual, "file hook is nil")
+	assert.Equal(t, expected.Fixtures, actual.Fixtures(), "Fixtures of file hook returns wrong value")
+	assert.Equal(t, expected.GetVariables, actual.GetVariables(), "GetVariables of file hook returns wrong value")
+	assert.Equal(t, expected.ServiceMocks, actual.ServiceMocks(func(s string) string { return s }),
+		"ServiceMocks of file hook returns wrong value")
+	compareScript(t, expected.Script, actual.Script())
 }
 
 func compareDatabaseCheckResult(t *testing.T, expected []databaseCheckResult, actual []models.DatabaseCheck) {
@@ -315,6 +497,61 @@ func compareDatabaseCheckResult(t *testing.T, expected []databaseCheckResult, ac
 			actual[idx].DbResponseJson(), "DbResponseJson for DbCheck #%d", idx)
 		compareComparisonParams(t, expected[idx].GetComparisonParams,
 			actual[idx].GetComparisonParams())
+		assert.Equal(t, expected[idx].GetEventually.Timeout,
+			actual[idx].GetEventually().Timeout(), "Eventually.Timeout for DbCheck #%d", idx)
+		assert.Equal(t, expected[idx].GetEventually.Interval,
+			actual[idx].GetEventually().Interval(), "Eventually.Interval for DbCheck #%d", idx)
+	}
+}
+
+func compareMessages(t *testing.T, expectedPublish []topicMessagesResult,
+	expectedChecks []messageCheckResult, actual models.TestInterface) {
+	compareBusMessages := func(expected []busMessageResult, actual []models.BusMessage) {
+		require.Len(t, actual, len(expected), "number of messages doesn't match")
+		for idx, msg := range actual {
+			assert.Equal(t, expected[idx].Key, msg.Key(), "Key of message #%d", idx)
+			assert.Equal(t, expected[idx].Value, msg.Value(), "Value of message #%d", idx)
+			assert.Equal(t, expected[idx].Headers, msg.Headers(), "Headers of message #%d", idx)
+		}
+	}
+
+	publish := actual.PublishMessages()
+	require.Len(t, publish, len(expectedPublish), "PublishMessages returns wrong number of items")
+	for idx, item := range publish {
+		assert.Equal(t, expectedPublish[idx].Topic, item.Topic(), "Topic of PublishMessages #%d", idx)
+		compareBusMessages(expectedPublish[idx].Messages, item.Messages())
+	}
+
+	checks := actual.GetMessageChecks()
+	require.Len(t, checks, len(expectedChecks), "GetMessageChecks returns wrong number of items")
+	for idx, check := range checks {
+		assert.Equal(t, expectedChecks[idx].Topic, check.Topic(), "Topic of MessageCheck #%d", idx)
+		assert.Equal(t, expectedChecks[idx].Timeout, check.Timeout(), "Timeout of MessageCheck #%d", idx)
+		compareComparisonParams(t, expectedChecks[idx].GetComparisonParams, check.GetComparisonParams())
+		compareBusMessages(expectedChecks[idx].Messages, check.Messages())
+	}
+}
+
+func compareMockRequests(t *testing.T, expected []mockRequestsCheckResult, actual []models.MockRequestsCheck) {
+	require.Len(t, actual, len(expected), "GetMockRequests returns wrong number of items")
+	for idx, check := range actual {
+		assert.Equal(t, expected[idx].ServiceName, check.ServiceName(), "ServiceName of MockRequestsCheck #%d", idx)
+		count, ok := check.Count()
+		assert.Equal(t, expected[idx].Count != nil, ok, "Count state of MockRequestsCheck #%d", idx)
+		if expected[idx].Count != nil {
+			assert.Equal(t, *expected[idx].Count, count, "Count of MockRequestsCheck #%d", idx)
+		}
+		compareComparisonParams(t, expected[idx].GetComparisonParams, check.GetComparisonParams())
+
+		requests := check.Requests()
+		require.Len(t, requests, len(expected[idx].Requests), "number of requests doesn't match")
+		for i, request := range requests {
+			assert.Equal(t, expected[idx].Requests[i].Method, request.Method(), "Method of request #%d", i)
+			assert.Equal(t, expected[idx].Requests[i].Path, request.Path(), "Path of request #%d", i)
+			assert.Equal(t, expected[idx].Requests[i].Query, request.Query(), "Query of request #%d", i)
+			assert.Equal(t, expected[idx].Requests[i].Headers, request.Headers(), "Headers of request #%d", i)
+			assert.Equal(t, expected[idx].Requests[i].Body, request.Body(), "Body of request #%d", i)
+		}
 	}
 }
 
@@ -0,0 +1,396 @@
+package yaml_file
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lansfy/gonkex/models"
+	"github.com/lansfy/gonkex/testloader"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RecordMarker can be used as expected response body to ask for recording of the actual response.
+const RecordMarker = "$record"
+
+const (
+	uuidPattern = "$matchRegexp(^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$)"
+	timePattern = "$matchTime(" + time.RFC3339 + ")"
+)
+
+var uuidRx = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// RecordMode defines which expectations are written by the recorder.
+type RecordMode int
+
+const (
+	// RecordMissing writes responses of tests without expected response
+	// (or with RecordMarker as expected body) into the YAML file (default mode).
+	RecordMissing RecordMode = 1 << iota
+	// RecordFiles rewrites files from responseFile section with actual responses.
+	RecordFiles
+)
+
+type RecorderOpts struct {
+	// Mode is a combination of RecordMissing and RecordFiles flags, RecordMissing by default.
+	Mode RecordMode
+	// Patterns enables replacement of volatile values in recorded JSON bodies:
+	// UUIDs are replaced with $matchRegexp and RFC 3339 timestamps with $matchTime.
+	Patterns bool
+}
+
+type yamlRecorder struct {
+	opts  RecorderOpts
+	mutex sync.Mutex
+}
+
+// NewRecorder creates recorder which writes actual responses into YAML files with tests
+// and files with expected responses.
+// Comments and formatting of the YAML file are preserved as far as possible.
+func NewRecorder(opts *RecorderOpts) testloader.RecorderInterface {
+	r := &yamlRecorder{}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Mode == 0 {
+		r.opts.Mode = RecordMissing
+	}
+	return r
+}
+
+func (r *yamlRecorder) NeedsRecording(t models.TestInterface) bool {
+	test, ok := t.(*testImpl)
+	if !ok || t.OneOfCase() || len(test.Steps) != 0 {
+		return false
+	}
+	if r.opts.Mode&RecordFiles != 0 && len(test.ResponseFile) != 0 {
+		return true
+	}
+	return r.opts.Mode&RecordMissing != 0 && needsRecording(t.GetResponses())
+}
+
+func needsRecording(responses map[int]string) bool {
+	if len(responses) == 0 {
+		return true
+	}
+	for _, body := range responses {
+		if body == RecordMarker {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *yamlRecorder) Record(t models.TestInterface, result *models.Result) (models.TestInterface, error) {
+	test, ok := t.Clone().(*testImpl)
+	if !ok {
+		return nil, errors.New("record response: unsupported test type")
+	}
+
+	body := result.ResponseBody
+	formatted, isJSON := formatJSONBody(body, r.opts.Patterns)
+	if isJSON {
+		body = formatted
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if fileName, ok := test.ResponseFile[result.ResponseStatusCode]; ok && r.opts.Mode&RecordFiles != 0 {
+		if isJSON {
+			body += "\n"
+		}
+		if err := os.WriteFile(fileName, []byte(body), 0o644); err != nil {
+			return nil, fmt.Errorf("update response file of test '%s': %w", test.Name, err)
+		}
+		test.Response = cloneResponses(test.Response)
+		test.Response[result.ResponseStatusCode] = body
+		return test, nil
+	}
+
+	if r.opts.Mode&RecordMissing == 0 || !needsRecording(test.Response) {
+		// response with unexpected status can't be recorded
+		return t, nil
+	}
+
+	responses := map[int]string{}
+	for status, value := range test.Response {
+		if value != RecordMarker {
+			responses[status] = value
+		}
+	}
+	responses[result.ResponseStatusCode] = body
+	test.Response = responses
+
+	var headers map[string]string
+	if _, ok := test.ResponseHeaders[result.ResponseStatusCode]; !ok && result.ResponseContentType != "" {
+		headers = map[string]string{"Content-Type": result.ResponseContentType}
+		test.ResponseHeaders = cloneResponseHeaders(test.ResponseHeaders)
+		test.ResponseHeaders[result.ResponseStatusCode] = headers
+	}
+
+	err := updateTestFile(test.Filename, test.Name, result.ResponseStatusCode, body, headers)
+	if err != nil {
+		return nil, fmt.Errorf("record response of test '%s' to file %s: %w", test.Name, test.Filename, err)
+	}
+	return test, nil
+}
+
+func cloneResponses(responses map[int]string) map[int]string {
+	res := map[int]string{}
+	for status, value := range responses {
+		res[status] = value
+	}
+	return res
+}
+
+func cloneResponseHeaders(headers map[int]map[string]string) map[int]map[string]string {
+	res := map[int]map[string]string{}
+	for status, value := range headers {
+		res[status] = value
+	}
+	return res
+}
+
+// updateTestFile writes the recorded response into the definition of the test with the given name.
+func updateTestFile(fileName, testName string, status int, body string, headers map[string]string) error {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return err
+	}
+
+	item, err := findRecordedTest(doc, testName)
+	if err != nil {
+		return err
+	}
+
+	statusKey := strconv.Itoa(status)
+	response := mappingValue(item, "response")
+	if response == nil || response.Kind != yaml.MappingNode {
+		response = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(item, "response", response)
+	}
+	removeRecordMarkers(response)
+	setMappingValue(response, statusKey, bodyNode(body))
+
+	if headers != nil {
+		responseHeaders := mappingValue(item, "responseHeaders")
+		if responseHeaders == nil || responseHeaders.Kind != yaml.MappingNode {
+			responseHeaders = &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(item, "responseHeaders", responseHeaders)
+		}
+		headersNode := &yaml.Node{Kind: yaml.MappingNode}
+		for name, value := range headers {
+			setMappingValue(headersNode, name, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+		}
+		setMappingValue(responseHeaders, statusKey, headersNode)
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	result := buf.Bytes()
+	if bytes.Contains(content, []byte("\n\n- ")) {
+		// encoder drops empty lines, so restore separation of tests
+		result = separateItems(result)
+	}
+	return os.WriteFile(fileName, result, 0o644)
+}
+
+// separateItems inserts empty line before every item of the top-level list (except the first one).
+// Comments placed right before the item are kept together with it.
+func separateItems(content []byte) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	var result []string
+	first := true
+	for _, line := range lines {
+		if strings.HasPrefix(line, "- ") {
+			if !first {
+				pos := len(result)
+				for pos > 0 && strings.HasPrefix(result[pos-1], "#") {
+					pos--
+				}
+				result = append(result[:pos], append([]string{"\n"}, result[pos:]...)...)
+			}
+			first = false
+		}
+		result = append(result, line)
+	}
+	return []byte(strings.Join(result, ""))
+}
+
+// findRecordedTest returns the first definition with the given name which still needs recording.
+func findRecordedTest(doc *yaml.Node, testName string) (*yaml.Node, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.SequenceNode {
+		return nil, errors.New("file must contain list of tests")
+	}
+
+	for _, item := range doc.Content[0].Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		name := mappingValue(item, "name")
+		if name == nil || name.Value != testName {
+			continue
+		}
+
+		response := mappingValue(item, "response")
+		if response == nil || len(response.Content) == 0 {
+			return item, nil
+		}
+		for i := 1; i < len(response.Content); i += 2 {
+			if response.Content[i].Value == RecordMarker {
+				return item, nil
+			}
+		}
+	}
+	return nil, errors.New("test definition not found")
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+func removeRecordMarkers(node *yaml.Node) {
+	var content []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i+1].Value != RecordMarker {
+			content = append(content, node.Content[i], node.Content[i+1])
+		}
+	}
+	node.Content = content
+}
+
+func bodyNode(body string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: body}
+	if strings.Contains(body, "\n") {
+		node.Style = yaml.LiteralStyle
+	} else {
+		node.Style = yaml.SingleQuotedStyle
+	}
+	return node
+}
+
+// formatJSONBody returns indented JSON (keeping the order of fields) if body is valid JSON.
+func formatJSONBody(body string, patterns bool) (string, bool) {
+	if !json.Valid([]byte(body)) {
+		return "", false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	buf := &bytes.Buffer{}
+	if err := writeJSONValue(decoder, buf, "", patterns); err != nil {
+		return "", false
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+func writeJSONValue(decoder *json.Decoder, buf *bytes.Buffer, indent string, patterns bool) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch v := token.(type) {
+	case json.Delim:
+		buf.WriteRune(rune(v))
+		count := 0
+		for decoder.More() {
+			if count != 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + indent + "  ")
+			if v == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				writeJSONString(buf, key.(string))
+				buf.WriteString(": ")
+			}
+			if err := writeJSONValue(decoder, buf, indent+"  ", patterns); err != nil {
+				return err
+			}
+			count++
+		}
+		closing, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if count != 0 {
+			buf.WriteString("\n" + indent)
+		}
+		buf.WriteRune(rune(closing.(json.Delim)))
+	case string:
+		if patterns {
+			v = volatilePattern(v)
+		}
+		writeJSONString(buf, v)
+	case json.Number:
+		buf.WriteString(v.String())
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	}
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, value string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	// remove newline added by encoder
+	buf.Truncate(buf.Len() - 1)
+}
+
+// volatilePattern replaces values which are different on every request with matchers.
+func volatilePattern(value string) string {
+	if uuidRx.MatchString(value) {
+		return uuidPattern
+	}
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return timePattern
+	}
+	return value
+}
@@ -0,0 +1,156 @@
+package yaml_file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lansfy/gonkex/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Recorder(t *testing.T) {
+	content, err := os.ReadFile("testdata/record/tests.yaml")
+	require.NoError(t, err)
+	fileName := filepath.Join(t.TempDir(), "tests.yaml")
+	require.NoError(t, os.WriteFile(fileName, content, 0o644))
+
+	tests, err := NewLoader(fileName).Load()
+	require.NoError(t, err)
+	require.Len(t, tests, 4)
+
+	results := []*models.Result{
+		{
+			ResponseStatusCode:  200,
+			ResponseContentType: "application/json",
+			ResponseBody: `{"id":"3f2b8e4c-9a1d-4c5e-8f7a-1b2c3d4e5f60","name":"John <admin>",` +
+				`"created":"2024-05-01T10:20:30.123Z","roles":["admin"],"meta":{},"age":42,"active":true,"parent":null}`,
+		},
+		{
+			ResponseStatusCode: 201,
+			ResponseBody:       `{"id": 5}`,
+		},
+		{
+			ResponseStatusCode:  404,
+			ResponseContentType: "text/plain",
+			ResponseBody:        "not found",
+		},
+	}
+
+	recorder := NewRecorder(&RecorderOpts{Patterns: true})
+	for i, test := range tests {
+		if i == len(results) {
+			require.False(t, recorder.NeedsRecording(test))
+			continue
+		}
+		require.True(t, recorder.NeedsRecording(test))
+
+		recorded, err := recorder.Record(test, results[i])
+		require.NoError(t, err)
+		require.False(t, recorder.NeedsRecording(recorded))
+		_, ok := recorded.GetResponse(results[i].ResponseStatusCode)
+		require.True(t, ok)
+	}
+
+	actual, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	expected, err := os.ReadFile("testdata/record/tests_expected.yaml")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+
+	// recorded file must be valid and doesn't need recording anymore
+	tests, err = NewLoader(fileName).Load()
+	require.NoError(t, err)
+	for _, test := range tests {
+		require.False(t, recorder.NeedsRecording(test))
+	}
+}
+
+func Test_Recorder_CasesAreIgnored(t *testing.T) {
+	tests, err := NewInMemoryLoader(map[string]string{
+		"cases.yaml": `
+- name: test with cases
+  method: GET
+  path: /user/{{ .id }}
+  cases:
+    - requestArgs:
+        id: 1
+`,
+	}, nil).Load()
+	require.NoError(t, err)
+	require.Len(t, tests, 1)
+	require.False(t, NewRecorder(nil).NeedsRecording(tests[0]))
+}
+
+func Test_formatJSONBody(t *testing.T) {
+	_, ok := formatJSONBody("not json", false)
+	require.False(t, ok)
+
+	actual, ok := formatJSONBody(`{"b":1,"a":["2024-05-01T10:20:30Z",[]]}`, false)
+	require.True(t, ok)
+	require.Equal(t, "{\n  \"b\": 1,\n  \"a\": [\n    \"2024-05-01T10:20:30Z\",\n    []\n  ]\n}", actual)
+}
+
+func Test_Recorder_UpdateFiles(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "tests.yaml")
+	content := `
+- name: test with response file
+  method: GET
+  path: /users
+  responseFile:
+    200: golden/users.json
+    404: golden/not_found.txt
+
+- name: test without response
+  method: GET
+  path: /users
+`
+	require.NoError(t, os.WriteFile(fileName, []byte(content), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "golden"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "golden", "users.json"), []byte(`{}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "golden", "not_found.txt"), []byte(`old`), 0o644))
+
+	tests, err := NewLoader(fileName).Load()
+	require.NoError(t, err)
+	require.Len(t, tests, 2)
+
+	recorder := NewRecorder(&RecorderOpts{Mode: RecordFiles})
+	require.True(t, recorder.NeedsRecording(tests[0]))
+	require.False(t, recorder.NeedsRecording(tests[1]), "only files must be updated")
+
+	recorded, err := recorder.Record(tests[0], &models.Result{
+		ResponseStatusCode: 200,
+		ResponseBody:       `{"users":[{"id":1}]}`,
+	})
+	require.NoError(t, err)
+	expected := "{\n  \"users\": [\n    {\n      \"id\": 1\n    }\n  ]\n}\n"
+	body, _ := recorded.GetResponse(200)
+	require.Equal(t, expected, body)
+
+	recorded, err = recorder.Record(tests[0], &models.Result{
+		ResponseStatusCode: 404,
+		ResponseBody:       "not found",
+	})
+	require.NoError(t, err)
+	body, _ = recorded.GetResponse(404)
+	require.Equal(t, "not found", body)
+
+	// status without response file is not recorded
+	recorded, err = recorder.Record(tests[0], &models.Result{ResponseStatusCode: 500})
+	require.NoError(t, err)
+	require.Equal(t, tests[0], recorded)
+
+	actual, err := os.ReadFile(filepath.Join(dir, "golden", "users.json"))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(actual))
+	actual, err = os.ReadFile(filepath.Join(dir, "golden", "not_found.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "not found", string(actual))
+
+	// YAML file must stay untouched
+	actual, err = os.ReadFile(fileName)
+	require.NoError(t, err)
+	require.Equal(t, content, string(actual))
+}
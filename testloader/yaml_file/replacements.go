@@ -83,3 +83,95 @@ func performDbResponses(responses []string, perform func(string) string) []strin
 	}
 	return res
 }
+
+func performWebSocket(def *WebSocketDefinition, perform func(string) string) *WebSocketDefinition {
+	res := &WebSocketDefinition{
+		Timeout: def.Timeout,
+		Steps:   make([]WebSocketStepDefinition, len(def.Steps)),
+	}
+	performPtr := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		newValue := perform(*value)
+		return &newValue
+	}
+	for i, step := range def.Steps {
+		step.Send = performPtr(step.Send)
+		step.Receive = performPtr(step.Receive)
+		res.Steps[i] = step
+	}
+	return res
+}
+
+func performStream(def *StreamDefinition, perform func(string) string) *StreamDefinition {
+	res := *def
+	res.Events = make([]StreamEventDefinition, len(def.Events))
+	for i, event := range def.Events {
+		event.Event = perform(event.Event)
+		event.Data = perform(event.Data)
+		res.Events[i] = event
+	}
+	return &res
+}
+
+func performPublishMessages(defs []TopicMessagesDefinition, perform func(string) string) []TopicMessagesDefinition {
+	if defs == nil {
+		return nil
+	}
+	res := make([]TopicMessagesDefinition, len(defs))
+	for i, def := range defs {
+		def.Topic = perform(def.Topic)
+		def.Messages = performMessages(def.Messages, perform)
+		res[i] = def
+	}
+	return res
+}
+
+func performMessageChecks(defs []MessageCheckDefinition, perform func(string) string) []MessageCheckDefinition {
+	if defs == nil {
+		return nil
+	}
+	res := make([]MessageCheckDefinition, len(defs))
+	for i, def := range defs {
+		def.Topic = perform(def.Topic)
+		def.Messages = performMessages(def.Messages, perform)
+		res[i] = def
+	}
+	return res
+}
+
+func performMockRequests(defs map[string]MockRequestsDefinition,
+	perform func(string) string) map[string]MockRequestsDefinition {
+	if defs == nil {
+		return nil
+	}
+	res := make(map[string]MockRequestsDefinition, len(defs))
+	for name, def := range defs {
+		if def.Requests != nil {
+			requests := make([]MockRequestDefinition, len(def.Requests))
+			for i, request := range def.Requests {
+				request.Method = perform(request.Method)
+				request.Path = perform(request.Path)
+				request.Query = perform(request.Query)
+				request.Headers = performHeaders(request.Headers, perform)
+				request.Body = perform(request.Body)
+				requests[i] = request
+			}
+			def.Requests = requests
+		}
+		res[name] = def
+	}
+	return res
+}
+
+func performMessages(defs []MessageDefinition, perform func(string) string) []MessageDefinition {
+	res := make([]MessageDefinition, len(defs))
+	for i, def := range defs {
+		def.Key = perform(def.Key)
+		def.Value = perform(def.Value)
+		def.Headers = performHeaders(def.Headers, perform)
+		res[i] = def
+	}
+	return res
+}
@@ -0,0 +1,127 @@
+package yaml_file
+
+import (
+	"fmt"
+)
+
+// stepDefinition returns definition of the step request. Headers, cookies and client settings
+// of the test are inherited by HTTP and WebSocket steps, values of the step take precedence.
+func stepDefinition(def *TestDefinition, idx int) *TestDefinition {
+	step := &def.Steps[idx]
+	name := step.Name
+	if name == "" {
+		name = fmt.Sprintf("#%d", idx+1)
+	}
+
+	res := &TestDefinition{
+		Name:             name,
+		Method:           step.Method,
+		Path:             step.Path,
+		Query:            step.Query,
+		Request:          step.Request,
+		Form:             step.Form,
+		Headers:          step.Headers,
+		Cookies:          step.Cookies,
+		Response:         step.Response,
+		ResponseHeaders:  step.ResponseHeaders,
+		ComparisonParams: step.ComparisonParams,
+		VariablesToSet:   step.VariablesToSet,
+		Mocks:            step.Mocks,
+		GRPC:             step.GRPC,
+		WebSocket:        step.WebSocket,
+		Stream:           step.Stream,
+		LineNumber:       def.LineNumber,
+	}
+	if step.GRPC == nil {
+		res.Headers = mergeMaps(def.Headers, step.Headers)
+		res.Cookies = mergeMaps(def.Cookies, step.Cookies)
+		res.Client = def.Client
+	}
+	return res
+}
+
+func mergeMaps(base, values map[string]string) map[string]string {
+	if len(base) == 0 {
+		return values
+	}
+	res := map[string]string{}
+	for key, value := range base {
+		res[key] = value
+	}
+	for key, value := range values {
+		res[key] = value
+	}
+	return res
+}
+
+// validateSteps checks the multi-step test. Fields of the request can be used only inside steps.
+func validateSteps(def *TestDefinition) error {
+	if len(def.Steps) == 0 {
+		return nil
+	}
+
+	requestFields := []struct {
+		name string
+		used bool
+	}{
+		{"method", def.Method != ""},
+		{"path", def.Path != ""},
+		{"query", def.Query != ""},
+		{"request", def.Request != ""},
+		{"form", def.Form != nil},
+		{"response", len(def.Response) != 0},
+		{"responseFile", len(def.ResponseFile) != 0},
+		{"responseHeaders", len(def.ResponseHeaders) != 0},
+		{"variables_to_set", len(def.VariablesToSet) != 0},
+		{"grpc", def.GRPC != nil},
+		{"websocket", def.WebSocket != nil},
+		{"stream", def.Stream != nil},
+	}
+	for _, field := range requestFields {
+		if field.used {
+			return fmt.Errorf("field '%s' can't be used together with 'steps', move it to the step", field.name)
+		}
+	}
+
+	for idx := range def.Steps {
+		step := stepDefinition(def, idx)
+		for _, validate := range []func(*TestDefinition) error{validateGRPC, validateWebSocket, validateStream} {
+			if err := validate(step); err != nil {
+				return fmt.Errorf("step '%s': %w", step.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// substituteStepsArgs substitutes requestArgs of the case to requests of the steps.
+func substituteStepsArgs(opts *LoaderOpts, steps []StepDefinition,
+	args map[string]interface{}) ([]StepDefinition, error) {
+	res := make([]StepDefinition, len(steps))
+	for idx, step := range steps {
+		var err error
+		prefix := fmt.Sprintf("steps[%d].", idx)
+		step.Path, err = substituteArgs(opts, prefix+"path", step.Path, args)
+		if err != nil {
+			return nil, err
+		}
+		step.Query, err = substituteArgs(opts, prefix+"query", step.Query, args)
+		if err != nil {
+			return nil, err
+		}
+		step.Request, err = substituteArgs(opts, prefix+"request", step.Request, args)
+		if err != nil {
+			return nil, err
+		}
+		step.Headers, err = substituteArgsToMap(opts, prefix+"headers", step.Headers, args)
+		if err != nil {
+			return nil, err
+		}
+		step.Cookies, err = substituteArgsToMap(opts, prefix+"cookies", step.Cookies, args)
+		if err != nil {
+			return nil, err
+		}
+		res[idx] = step
+	}
+	return res, nil
+}
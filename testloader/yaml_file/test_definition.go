@@ -5,40 +5,76 @@ import (
 )
 
 type TestDefinition struct {
-	Name               string                    `json:"name" yaml:"name"`
-	Description        string                    `json:"description" yaml:"description"`
-	Status             StatusEnum                `json:"status" yaml:"status"`
-	Variables          map[string]string         `json:"variables" yaml:"variables"`
-	VariablesToSet     VariablesToSet            `json:"variables_to_set" yaml:"variables_to_set"`
-	Form               *Form                     `json:"form" yaml:"form"`
-	Method             string                    `json:"method" yaml:"method"`
-	Path               string                    `json:"path" yaml:"path"`
-	Query              string                    `json:"query" yaml:"query"`
-	Request            string                    `json:"request" yaml:"request"`
-	Response           map[int]string            `json:"response" yaml:"response"`
-	ResponseHeaders    map[int]map[string]string `json:"responseHeaders" yaml:"responseHeaders"`
-	BeforeScript       ScriptParams              `json:"beforeScript" yaml:"beforeScript"`
-	AfterRequestScript ScriptParams              `json:"afterRequestScript" yaml:"afterRequestScript"`
-	Headers            map[string]string         `json:"headers" yaml:"headers"`
-	Cookies            map[string]string         `json:"cookies" yaml:"cookies"`
-	Cases              []CaseData                `json:"cases" yaml:"cases"`
-	ComparisonParams   compare.Params            `json:"comparisonParams" yaml:"comparisonParams"`
-	Fixtures           []string                  `json:"fixtures" yaml:"fixtures"`
-	Mocks              map[string]interface{}    `json:"mocks" yaml:"mocks"`
-	MocksParams        MocksParams               `json:"mocksParams" yaml:"mocksParams"`
-	Pause              Duration                  `json:"pause" yaml:"pause"`
-	AfterRequestPause  Duration                  `json:"afterRequestPause" yaml:"afterRequestPause"`
-	DbQuery            string                    `json:"dbQuery" yaml:"dbQuery"`
-	DbResponse         []string                  `json:"dbResponse" yaml:"dbResponse"`
-	DbChecks           []DatabaseCheck           `json:"dbChecks" yaml:"dbChecks"`
-	RetryPolicy        RetryPolicy               `json:"retryPolicy" yaml:"retryPolicy"`
-	Meta               map[string]interface{}    `json:"meta" yaml:"meta"`
-	LineNumber         int                       `json:"-" yaml:"-"`
+	Name               string                            `json:"name" yaml:"name"`
+	Description        string                            `json:"description" yaml:"description"`
+	Status             StatusEnum                        `json:"status" yaml:"status"`
+	Tags               []string                          `json:"tags" yaml:"tags"`
+	DependsOn          []string                          `json:"dependsOn" yaml:"dependsOn"`
+	Variables          map[string]string                 `json:"variables" yaml:"variables"`
+	VariablesToSet     VariablesToSet                    `json:"variables_to_set" yaml:"variables_to_set"`
+	Form               *Form                             `json:"form" yaml:"form"`
+	Method             string                            `json:"method" yaml:"method"`
+	Path               string                            `json:"path" yaml:"path"`
+	Query              string                            `json:"query" yaml:"query"`
+	Request            string                            `json:"request" yaml:"request"`
+	Response           map[int]string                    `json:"response" yaml:"response"`
+	ResponseFile       map[int]string                    `json:"responseFile" yaml:"responseFile"`
+	ResponseHeaders    map[int]map[string]string         `json:"responseHeaders" yaml:"responseHeaders"`
+	BeforeScript       ScriptParams                      `json:"beforeScript" yaml:"beforeScript"`
+	AfterRequestScript ScriptParams                      `json:"afterRequestScript" yaml:"afterRequestScript"`
+	Headers            map[string]string                 `json:"headers" yaml:"headers"`
+	Cookies            map[string]string                 `json:"cookies" yaml:"cookies"`
+	Cases              []CaseData                        `json:"cases" yaml:"cases"`
+	ComparisonParams   compare.Params                    `json:"comparisonParams" yaml:"comparisonParams"`
+	Fixtures           []string                          `json:"fixtures" yaml:"fixtures"`
+	Mocks              map[string]interface{}            `json:"mocks" yaml:"mocks"`
+	MocksParams        MocksParams                       `json:"mocksParams" yaml:"mocksParams"`
+	Pause              Duration                          `json:"pause" yaml:"pause"`
+	AfterRequestPause  Duration                          `json:"afterRequestPause" yaml:"afterRequestPause"`
+	DbQuery            string                            `json:"dbQuery" yaml:"dbQuery"`
+	DbResponse         []string                          `json:"dbResponse" yaml:"dbResponse"`
+	DbChecks           []DatabaseCheck                   `json:"dbChecks" yaml:"dbChecks"`
+	PublishMessages    []TopicMessagesDefinition         `json:"publishMessages" yaml:"publishMessages"`
+	MessageChecks      []MessageCheckDefinition          `json:"messageChecks" yaml:"messageChecks"`
+	MockRequests       map[string]MockRequestsDefinition `json:"mockRequests" yaml:"mockRequests"`
+	RetryPolicy        RetryPolicy                       `json:"retryPolicy" yaml:"retryPolicy"`
+	ResponseTime       ResponseTimeParams                `json:"responseTime" yaml:"responseTime"`
+	Client             ClientParams                      `json:"client" yaml:"client"`
+	GRPC               *GRPCDefinition                   `json:"grpc" yaml:"grpc"`
+	WebSocket          *WebSocketDefinition              `json:"websocket" yaml:"websocket"`
+	Stream             *StreamDefinition                 `json:"stream" yaml:"stream"`
+	Steps              []StepDefinition                  `json:"steps" yaml:"steps"`
+	Meta               map[string]interface{}            `json:"meta" yaml:"meta"`
+	BeforeAll          *FileHookDefinition               `json:"beforeAll" yaml:"beforeAll"`
+	AfterAll           *FileHookDefinition               `json:"afterAll" yaml:"afterAll"`
+	LineNumber         int                               `json:"-" yaml:"-"`
+}
+
+// StepDefinition describes one request of the multi-step test.
+// Headers, cookies and client settings of the test are inherited by every step.
+type StepDefinition struct {
+	Name             string                    `json:"name" yaml:"name"`
+	Method           string                    `json:"method" yaml:"method"`
+	Path             string                    `json:"path" yaml:"path"`
+	Query            string                    `json:"query" yaml:"query"`
+	Request          string                    `json:"request" yaml:"request"`
+	Form             *Form                     `json:"form" yaml:"form"`
+	Headers          map[string]string         `json:"headers" yaml:"headers"`
+	Cookies          map[string]string         `json:"cookies" yaml:"cookies"`
+	Response         map[int]string            `json:"response" yaml:"response"`
+	ResponseHeaders  map[int]map[string]string `json:"responseHeaders" yaml:"responseHeaders"`
+	ComparisonParams compare.Params            `json:"comparisonParams" yaml:"comparisonParams"`
+	VariablesToSet   VariablesToSet            `json:"variables_to_set" yaml:"variables_to_set"`
+	Mocks            map[string]interface{}    `json:"mocks" yaml:"mocks"`
+	GRPC             *GRPCDefinition           `json:"grpc" yaml:"grpc"`
+	WebSocket        *WebSocketDefinition      `json:"websocket" yaml:"websocket"`
+	Stream           *StreamDefinition         `json:"stream" yaml:"stream"`
 }
 
 type CaseData struct {
 	Name                   string                         `json:"name" yaml:"name"`
 	Description            string                         `json:"description" yaml:"description"`
+	Tags                   []string                       `json:"tags" yaml:"tags"`
 	RequestArgs            map[string]interface{}         `json:"requestArgs" yaml:"requestArgs"`
 	ResponseArgs           map[int]map[string]interface{} `json:"responseArgs" yaml:"responseArgs"`
 	BeforeScriptArgs       map[string]interface{}         `json:"beforeScriptArgs" yaml:"beforeScriptArgs"`
@@ -58,6 +94,12 @@ type DatabaseCheck struct {
 	DbQuery          string         `json:"dbQuery" yaml:"dbQuery"`
 	DbResponse       []string       `json:"dbResponse" yaml:"dbResponse"`
 	ComparisonParams compare.Params `json:"comparisonParams" yaml:"comparisonParams"`
+	Eventually       Eventually     `json:"eventually" yaml:"eventually"`
+}
+
+type Eventually struct {
+	Timeout  Duration `json:"timeout" yaml:"timeout"`
+	Interval Duration `json:"interval" yaml:"interval"`
 }
 
 type RetryPolicy struct {
@@ -66,11 +108,101 @@ type RetryPolicy struct {
 	SuccessInRow int      `json:"successInRow" yaml:"successInRow"`
 }
 
+type ResponseTimeParams struct {
+	MaxDuration Duration `json:"maxDuration" yaml:"maxDuration"`
+}
+
+type ClientParams struct {
+	CAFile     string `json:"caFile" yaml:"caFile"`
+	CertFile   string `json:"certFile" yaml:"certFile"`
+	KeyFile    string `json:"keyFile" yaml:"keyFile"`
+	ServerName string `json:"serverName" yaml:"serverName"`
+	Protocol   string `json:"protocol" yaml:"protocol"`
+}
+
+// GRPCDefinition describes the gRPC call made by the test instead of HTTP request.
+type GRPCDefinition struct {
+	Method   string            `json:"method" yaml:"method"`
+	Metadata map[string]string `json:"metadata" yaml:"metadata"`
+	Timeout  Duration          `json:"timeout" yaml:"timeout"`
+}
+
+// WebSocketDefinition describes the conversation made by the test over WebSocket connection.
+type WebSocketDefinition struct {
+	Timeout Duration                  `json:"timeout" yaml:"timeout"`
+	Steps   []WebSocketStepDefinition `json:"steps" yaml:"steps"`
+}
+
+type WebSocketStepDefinition struct {
+	Send             *string        `json:"send" yaml:"send"`
+	Receive          *string        `json:"receive" yaml:"receive"`
+	Timeout          Duration       `json:"timeout" yaml:"timeout"`
+	ComparisonParams compare.Params `json:"comparisonParams" yaml:"comparisonParams"`
+}
+
+// StreamDefinition describes expected events of the streaming response.
+type StreamDefinition struct {
+	Format         string                  `json:"format" yaml:"format"`
+	Timeout        Duration                `json:"timeout" yaml:"timeout"`
+	IgnoreOrdering bool                    `json:"ignoreOrdering" yaml:"ignoreOrdering"`
+	Events         []StreamEventDefinition `json:"events" yaml:"events"`
+}
+
+type StreamEventDefinition struct {
+	Event            string         `json:"event" yaml:"event"`
+	Data             string         `json:"data" yaml:"data"`
+	ComparisonParams compare.Params `json:"comparisonParams" yaml:"comparisonParams"`
+}
+
+// TopicMessagesDefinition describes messages published to the topic of the message bus before the request.
+type TopicMessagesDefinition struct {
+	Topic    string              `json:"topic" yaml:"topic"`
+	Messages []MessageDefinition `json:"messages" yaml:"messages"`
+}
+
+// MessageCheckDefinition describes messages expected to be produced to the topic of the message bus.
+type MessageCheckDefinition struct {
+	Topic            string              `json:"topic" yaml:"topic"`
+	Messages         []MessageDefinition `json:"messages" yaml:"messages"`
+	Timeout          Duration            `json:"timeout" yaml:"timeout"`
+	ComparisonParams compare.Params      `json:"comparisonParams" yaml:"comparisonParams"`
+}
+
+// MockRequestsDefinition describes requests expected to be received by the mock service.
+type MockRequestsDefinition struct {
+	Count            *int                    `json:"count" yaml:"count"`
+	Requests         []MockRequestDefinition `json:"requests" yaml:"requests"`
+	ComparisonParams compare.Params          `json:"comparisonParams" yaml:"comparisonParams"`
+}
+
+type MockRequestDefinition struct {
+	Method  string            `json:"method" yaml:"method"`
+	Path    string            `json:"path" yaml:"path"`
+	Query   string            `json:"query" yaml:"query"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	Body    string            `json:"body" yaml:"body"`
+}
+
+type MessageDefinition struct {
+	Key     string            `json:"key" yaml:"key"`
+	Value   string            `json:"value" yaml:"value"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+}
+
 type ScriptParams struct {
 	Path    string   `json:"path" yaml:"path"`
 	Timeout Duration `json:"timeout" yaml:"timeout"`
 }
 
+// FileHookDefinition describes actions which are performed once per file.
+// It is declared as a separate list item with single beforeAll or afterAll key.
+type FileHookDefinition struct {
+	Fixtures  []string               `json:"fixtures" yaml:"fixtures"`
+	Mocks     map[string]interface{} `json:"mocks" yaml:"mocks"`
+	Script    ScriptParams           `json:"script" yaml:"script"`
+	Variables map[string]string      `json:"variables" yaml:"variables"`
+}
+
 type MocksParams struct {
 	ShareState bool `json:"shareState" yaml:"shareState"`
 }
@@ -1,6 +1,7 @@
 package yaml_file
 
 import (
+	"sort"
 	"strings"
 	"time"
 
@@ -9,9 +10,10 @@ import (
 )
 
 type dbCheck struct {
-	query    string
-	response []string
-	params   compare.Params
+	query      string
+	response   []string
+	params     compare.Params
+	eventually Eventually
 }
 
 func (c *dbCheck) DbQueryString() string {
@@ -26,6 +28,22 @@ func (c *dbCheck) GetComparisonParams() models.ComparisonParams {
 	return &cmpParams{c.params}
 }
 
+func (c *dbCheck) GetEventually() models.Eventually {
+	return &eventually{c.eventually}
+}
+
+type eventually struct {
+	params Eventually
+}
+
+func (e *eventually) Timeout() time.Duration {
+	return e.params.Timeout.Duration
+}
+
+func (e *eventually) Interval() time.Duration {
+	return e.params.Interval.Duration
+}
+
 type cmpParams struct {
 	params compare.Params
 }
@@ -58,6 +76,253 @@ func (r *retry) SuccessCount() int {
 	return r.params.SuccessInRow
 }
 
+type responseTime struct {
+	params ResponseTimeParams
+}
+
+func (r *responseTime) MaxDuration() time.Duration {
+	return r.params.MaxDuration.Duration
+}
+
+type clientParams struct {
+	params ClientParams
+}
+
+func (c *clientParams) CAFile() string {
+	return c.params.CAFile
+}
+
+func (c *clientParams) CertFile() string {
+	return c.params.CertFile
+}
+
+func (c *clientParams) KeyFile() string {
+	return c.params.KeyFile
+}
+
+func (c *clientParams) ServerName() string {
+	return c.params.ServerName
+}
+
+func (c *clientParams) Protocol() string {
+	return c.params.Protocol
+}
+
+type grpcParams struct {
+	def *GRPCDefinition
+}
+
+func (g *grpcParams) FullMethod() string {
+	return g.def.Method
+}
+
+func (g *grpcParams) Metadata() map[string]string {
+	return g.def.Metadata
+}
+
+func (g *grpcParams) Timeout() time.Duration {
+	return g.def.Timeout.Duration
+}
+
+type webSocket struct {
+	def *WebSocketDefinition
+}
+
+func (w *webSocket) Steps() []models.WebSocketStep {
+	steps := make([]models.WebSocketStep, len(w.def.Steps))
+	for i := range w.def.Steps {
+		step := &webSocketStep{
+			params:  w.def.Steps[i].ComparisonParams,
+			timeout: w.def.Steps[i].Timeout.Duration,
+		}
+		if step.timeout == 0 {
+			step.timeout = w.def.Timeout.Duration
+		}
+		if w.def.Steps[i].Send != nil {
+			step.send = *w.def.Steps[i].Send
+		}
+		if w.def.Steps[i].Receive != nil {
+			step.receive = *w.def.Steps[i].Receive
+		}
+		steps[i] = step
+	}
+	return steps
+}
+
+type webSocketStep struct {
+	send    string
+	receive string
+	timeout time.Duration
+	params  compare.Params
+}
+
+func (s *webSocketStep) Send() string {
+	return s.send
+}
+
+func (s *webSocketStep) Receive() string {
+	return s.receive
+}
+
+func (s *webSocketStep) Timeout() time.Duration {
+	return s.timeout
+}
+
+func (s *webSocketStep) GetComparisonParams() models.ComparisonParams {
+	return &cmpParams{s.params}
+}
+
+type stream struct {
+	def *StreamDefinition
+}
+
+func (s *stream) Format() string {
+	return s.def.Format
+}
+
+func (s *stream) Timeout() time.Duration {
+	return s.def.Timeout.Duration
+}
+
+func (s *stream) IgnoreOrdering() bool {
+	return s.def.IgnoreOrdering
+}
+
+func (s *stream) Events() []models.ExpectedEvent {
+	events := make([]models.ExpectedEvent, len(s.def.Events))
+	for i := range s.def.Events {
+		events[i] = &expectedEvent{&s.def.Events[i]}
+	}
+	return events
+}
+
+type expectedEvent struct {
+	def *StreamEventDefinition
+}
+
+func (e *expectedEvent) Event() string {
+	return e.def.Event
+}
+
+func (e *expectedEvent) Data() string {
+	return e.def.Data
+}
+
+func (e *expectedEvent) GetComparisonParams() models.ComparisonParams {
+	return &cmpParams{e.def.ComparisonParams}
+}
+
+type topicMessages struct {
+	def *TopicMessagesDefinition
+}
+
+func (m *topicMessages) Topic() string {
+	return m.def.Topic
+}
+
+func (m *topicMessages) Messages() []models.BusMessage {
+	return busMessages(m.def.Messages)
+}
+
+type messageCheck struct {
+	def *MessageCheckDefinition
+}
+
+func (c *messageCheck) Topic() string {
+	return c.def.Topic
+}
+
+func (c *messageCheck) Messages() []models.BusMessage {
+	return busMessages(c.def.Messages)
+}
+
+func (c *messageCheck) Timeout() time.Duration {
+	return c.def.Timeout.Duration
+}
+
+func (c *messageCheck) GetComparisonParams() models.ComparisonParams {
+	return &cmpParams{c.def.ComparisonParams}
+}
+
+type mockRequestsCheck struct {
+	name string
+	def  *MockRequestsDefinition
+}
+
+func (c *mockRequestsCheck) ServiceName() string {
+	return c.name
+}
+
+func (c *mockRequestsCheck) Count() (int, bool) {
+	if c.def.Count == nil {
+		return 0, false
+	}
+	return *c.def.Count, true
+}
+
+func (c *mockRequestsCheck) Requests() []models.MockRequest {
+	if c.def.Requests == nil {
+		return nil
+	}
+	requests := make([]models.MockRequest, len(c.def.Requests))
+	for i := range c.def.Requests {
+		requests[i] = &mockRequest{&c.def.Requests[i]}
+	}
+	return requests
+}
+
+func (c *mockRequestsCheck) GetComparisonParams() models.ComparisonParams {
+	return &cmpParams{c.def.ComparisonParams}
+}
+
+type mockRequest struct {
+	def *MockRequestDefinition
+}
+
+func (r *mockRequest) Method() string {
+	return r.def.Method
+}
+
+func (r *mockRequest) Path() string {
+	return r.def.Path
+}
+
+func (r *mockRequest) Query() string {
+	return r.def.Query
+}
+
+func (r *mockRequest) Headers() map[string]string {
+	return r.def.Headers
+}
+
+func (r *mockRequest) Body() string {
+	return r.def.Body
+}
+
+func busMessages(defs []MessageDefinition) []models.BusMessage {
+	messages := make([]models.BusMessage, len(defs))
+	for i := range defs {
+		messages[i] = &busMessage{&defs[i]}
+	}
+	return messages
+}
+
+type busMessage struct {
+	def *MessageDefinition
+}
+
+func (m *busMessage) Key() string {
+	return m.def.Key
+}
+
+func (m *busMessage) Value() string {
+	return m.def.Value
+}
+
+func (m *busMessage) Headers() map[string]string {
+	return m.def.Headers
+}
+
 type formValues struct {
 	values *Form
 }
@@ -95,6 +360,38 @@ func (m *mocksParams) SkipMocksResetAfterTest() bool {
 	return !m.resetAfterTest
 }
 
+type fileHook struct {
+	def *FileHookDefinition
+}
+
+func (h *fileHook) Fixtures() []string {
+	return h.def.Fixtures
+}
+
+func (h *fileHook) Script() models.Script {
+	return &script{
+		params: h.def.Script,
+	}
+}
+
+func (h *fileHook) GetVariables() map[string]string {
+	return h.def.Variables
+}
+
+func (h *fileHook) ServiceMocks(perform func(string) string) map[string]interface{} {
+	if len(h.def.Mocks) == 0 {
+		return nil
+	}
+	mocks := deepClone(h.def.Mocks).(map[string]interface{})
+	performInterface(mocks, perform)
+	return mocks
+}
+
+type fileHooks struct {
+	beforeAll *FileHookDefinition
+	afterAll  *FileHookDefinition
+}
+
 type testImpl struct {
 	TestDefinition
 
@@ -105,6 +402,9 @@ type testImpl struct {
 	FirstTest   bool
 	LastTest    bool
 	IsOneOfCase bool
+	Hooks       *fileHooks
+	// Dependencies contains names of generated tests (including cases) listed in dependsOn
+	Dependencies []string
 
 	doNotResetMocksBeforeTest bool
 	doNotResetMocksAfterTest  bool
@@ -157,6 +457,14 @@ func (t *testImpl) Fixtures() []string {
 	return t.TestDefinition.Fixtures
 }
 
+func (t *testImpl) GetTags() []string {
+	return t.Tags
+}
+
+func (t *testImpl) GetDependencies() []string {
+	return t.Dependencies
+}
+
 func (t *testImpl) GetMeta(key string) interface{} {
 	if t.Meta != nil {
 		if val, ok := t.Meta[key]; ok {
@@ -209,6 +517,78 @@ func (t *testImpl) GetRetryPolicy() models.RetryPolicy {
 	return &retry{t.RetryPolicy}
 }
 
+func (t *testImpl) GetResponseTime() models.ResponseTime {
+	return &responseTime{t.ResponseTime}
+}
+
+func (t *testImpl) GetClientParams() models.ClientParams {
+	return &clientParams{t.Client}
+}
+
+func (t *testImpl) GetGRPC() models.GRPCParams {
+	if t.GRPC == nil {
+		return nil
+	}
+	return &grpcParams{t.GRPC}
+}
+
+func (t *testImpl) GetWebSocket() models.WebSocketParams {
+	if t.WebSocket == nil {
+		return nil
+	}
+	return &webSocket{t.WebSocket}
+}
+
+func (t *testImpl) PublishMessages() []models.TopicMessages {
+	res := make([]models.TopicMessages, len(t.TestDefinition.PublishMessages))
+	for i := range t.TestDefinition.PublishMessages {
+		res[i] = &topicMessages{&t.TestDefinition.PublishMessages[i]}
+	}
+	return res
+}
+
+func (t *testImpl) GetMessageChecks() []models.MessageCheck {
+	res := make([]models.MessageCheck, len(t.MessageChecks))
+	for i := range t.MessageChecks {
+		res[i] = &messageCheck{&t.MessageChecks[i]}
+	}
+	return res
+}
+
+func (t *testImpl) GetMockRequests() []models.MockRequestsCheck {
+	names := make([]string, 0, len(t.MockRequests))
+	for name := range t.MockRequests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]models.MockRequestsCheck, len(names))
+	for i, name := range names {
+		def := t.MockRequests[name]
+		res[i] = &mockRequestsCheck{name, &def}
+	}
+	return res
+}
+
+func (t *testImpl) GetStream() models.StreamParams {
+	if t.Stream == nil {
+		return nil
+	}
+	return &stream{t.Stream}
+}
+
+func (t *testImpl) GetSteps() []models.TestInterface {
+	steps := make([]models.TestInterface, 0, len(t.Steps))
+	for idx := range t.Steps {
+		steps = append(steps, &testImpl{
+			TestDefinition: *stepDefinition(&t.TestDefinition, idx),
+			Filename:       t.Filename,
+			DbChecks:       []models.DatabaseCheck{},
+		})
+	}
+	return steps
+}
+
 func (t *testImpl) ContentType() string {
 	for key, val := range t.TestDefinition.Headers {
 		if strings.EqualFold(key, "content-type") {
@@ -289,6 +669,10 @@ func (t *testImpl) ApplyVariables(perform func(string) string) {
 				IgnoreArraysOrdering: cmpOptions.IgnoreArraysOrdering(),
 				DisallowExtraFields:  cmpOptions.DisallowExtraFields(),
 			},
+			eventually: Eventually{
+				Timeout:  Duration{def.GetEventually().Timeout()},
+				Interval: Duration{def.GetEventually().Interval()},
+			},
 		}
 		dbChecks = append(dbChecks, newCheck)
 	}
@@ -307,6 +691,26 @@ func (t *testImpl) ApplyVariables(perform func(string) string) {
 		t.Form = performForm(t.Form, perform)
 	}
 
+	if t.GRPC != nil {
+		t.GRPC = &GRPCDefinition{
+			Method:   perform(t.GRPC.Method),
+			Metadata: performHeaders(t.GRPC.Metadata, perform),
+			Timeout:  t.GRPC.Timeout,
+		}
+	}
+
+	if t.WebSocket != nil {
+		t.WebSocket = performWebSocket(t.WebSocket, perform)
+	}
+
+	if t.Stream != nil {
+		t.Stream = performStream(t.Stream, perform)
+	}
+
+	t.TestDefinition.PublishMessages = performPublishMessages(t.TestDefinition.PublishMessages, perform)
+	t.MessageChecks = performMessageChecks(t.MessageChecks, perform)
+	t.MockRequests = performMockRequests(t.MockRequests, perform)
+
 	for _, definition := range t.ServiceMocks() {
 		performInterface(definition, perform)
 	}
@@ -323,3 +727,17 @@ func (t *testImpl) LastTestInFile() bool {
 func (t *testImpl) OneOfCase() bool {
 	return t.IsOneOfCase
 }
+
+func (t *testImpl) BeforeAll() models.FileHook {
+	if t.Hooks == nil || t.Hooks.beforeAll == nil {
+		return nil
+	}
+	return &fileHook{t.Hooks.beforeAll}
+}
+
+func (t *testImpl) AfterAll() models.FileHook {
+	if t.Hooks == nil || t.Hooks.afterAll == nil {
+		return nil
+	}
+	return &fileHook{t.Hooks.afterAll}
+}
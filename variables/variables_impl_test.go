@@ -119,3 +119,23 @@ func Test_Merge(t *testing.T) {
 	require.Equal(t, "value2", key2Value)
 	require.Equal(t, "value3", key3Value)
 }
+
+func Test_Scope(t *testing.T) {
+	parent := New()
+	parent.Set("key1", "parent1")
+	parent.Set("key2", "parent2")
+
+	scope := NewScope(parent)
+	scope.Merge(map[string]string{
+		"key2": "scope2",
+		"key3": "scope3",
+	})
+
+	require.Equal(t, 2, scope.Len())
+	require.Equal(t, "parent1 scope2 scope3 {{ $key4 }}",
+		scope.Substitute("{{ $key1 }} {{ $key2 }} {{ $key3 }} {{ $key4 }}"))
+
+	// parent must stay untouched
+	require.Equal(t, 2, parent.Len())
+	require.Equal(t, "parent2 {{ $key3 }}", parent.Substitute("{{ $key2 }} {{ $key3 }}"))
+}
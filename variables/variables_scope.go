@@ -0,0 +1,43 @@
+package variables
+
+// scopeImpl keeps its own set of variables on top of a read-only parent.
+type scopeImpl struct {
+	parent    Variables
+	variables map[string]string
+}
+
+// NewScope creates a new Variables instance which stores its own values and falls back
+// to the parent for variables not defined in the scope.
+// Changes made through the scope never affect the parent, so many scopes can share one parent
+// as long as the parent is not modified while scopes are in use.
+func NewScope(parent Variables) Variables {
+	return &scopeImpl{
+		parent:    parent,
+		variables: make(map[string]string),
+	}
+}
+
+func (s *scopeImpl) Set(name, value string) {
+	s.variables[name] = value
+}
+
+func (s *scopeImpl) Merge(variables map[string]string) {
+	for n, v := range variables {
+		s.variables[n] = v
+	}
+}
+
+// Len returns the number of variables defined in the scope itself (parent variables are not counted).
+func (s *scopeImpl) Len() int {
+	return len(s.variables)
+}
+
+func (s *scopeImpl) Substitute(str string) string {
+	str = variableRx.ReplaceAllStringFunc(str, func(found string) string {
+		if val, ok := s.variables[getVarName(found)]; ok {
+			return val
+		}
+		return found
+	})
+	return s.parent.Substitute(str)
+}